@@ -2,6 +2,7 @@ package alarm
 
 import (
 	"GinDemo/common/function"
+	"GinDemo/common/redact"
 	"encoding/json"
 	"fmt"
 	"path/filepath"
@@ -13,7 +14,8 @@ type errorString struct {
 	s string
 }
 
-type errorInfo struct {
+// ErrorInfo 告警信息
+type ErrorInfo struct {
 	Time     string `json:"time"`
 	Alarm    string `json:"alarm"`
 	Message  string `json:"message"`
@@ -68,10 +70,10 @@ func alarm(level string, text string, skip int) {
 		functionName = strings.TrimPrefix(functionName, ".")
 	}
 
-	var msg = errorInfo{
+	var msg = ErrorInfo{
 		Time:     currentTime,
 		Alarm:    level,
-		Message:  text,
+		Message:  redact.String(text),
 		Filename: fileName,
 		Line:     line,
 		Funcname: functionName,
@@ -84,4 +86,7 @@ func alarm(level string, text string, skip int) {
 
 	errorJsonInfo := string(jsons)
 	fmt.Println(errorJsonInfo)
+
+	// 发送到等级对应的告警通道
+	dispatch(msg)
 }
@@ -0,0 +1,72 @@
+package alarm
+
+import (
+	"crypto/tls"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+// emailNotifier 通过 SMTP 发送告警邮件，465 端口使用 SSL，其余端口使用 STARTTLS
+type emailNotifier struct {
+	host     string
+	port     string
+	user     string
+	password string
+	to       []string
+}
+
+func (n *emailNotifier) Send(info ErrorInfo) error {
+	subject := "[" + info.Alarm + "] " + firstLine(info.Message)
+	msg := []byte("From: " + n.user + "\r\n" +
+		"To: " + strings.Join(n.to, ",") + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n\r\n" +
+		content(info))
+
+	addr := net.JoinHostPort(n.host, n.port)
+	auth := smtp.PlainAuth("", n.user, n.password, n.host)
+	if n.port != "465" {
+		return smtp.SendMail(addr, auth, n.user, n.to, msg)
+	}
+
+	conn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: n.host})
+	if err != nil {
+		return err
+	}
+	client, err := smtp.NewClient(conn, n.host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err = client.Auth(auth); err != nil {
+		return err
+	}
+	if err = client.Mail(n.user); err != nil {
+		return err
+	}
+	for _, to := range n.to {
+		if err = client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = writer.Write(msg); err != nil {
+		return err
+	}
+	if err = writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func firstLine(str string) string {
+	if i := strings.IndexAny(str, "\r\n"); i >= 0 {
+		return str[:i]
+	}
+	return str
+}
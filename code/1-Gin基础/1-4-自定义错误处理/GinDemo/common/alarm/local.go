@@ -0,0 +1,38 @@
+package alarm
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// fileNotifier 本地替身通道，将告警按行写入文件，便于开发和测试时检查
+type fileNotifier struct {
+	channel  string
+	filename string
+	mu       sync.Mutex
+}
+
+func (n *fileNotifier) Send(info ErrorInfo) error {
+	line, err := json.Marshal(struct {
+		Channel string `json:"channel"`
+		ErrorInfo
+	}{n.channel, info})
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if err = os.MkdirAll(filepath.Dir(n.filename), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(n.filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,71 @@
+package alarm
+
+import (
+	"GinDemo/config"
+	"fmt"
+	"strings"
+)
+
+// Notifier 告警通道
+type Notifier interface {
+	Send(info ErrorInfo) error
+}
+
+// 告警正文
+func content(info ErrorInfo) string {
+	return fmt.Sprintf("[%s] %s %s\n%s\n%s:%d %s",
+		info.Alarm, config.APP_NAME, info.Time, info.Message, info.Filename, info.Line, info.Funcname)
+}
+
+// 按配置创建告警通道，local 模式下所有通道都写入本地文件
+func newNotifiers() map[string]Notifier {
+	if config.ALARM_MODE == "local" {
+		file := config.ALARM_LOCAL_FILE
+		return map[string]Notifier{
+			"email":   &fileNotifier{channel: "email", filename: file},
+			"sms":     &fileNotifier{channel: "sms", filename: file},
+			"webhook": &fileNotifier{channel: "webhook", filename: file},
+		}
+	}
+
+	notifiers := map[string]Notifier{}
+	if config.ALARM_SMTP_HOST != "" && config.ALARM_SMTP_TO != "" {
+		notifiers["email"] = &emailNotifier{
+			host:     config.ALARM_SMTP_HOST,
+			port:     config.ALARM_SMTP_PORT,
+			user:     config.ALARM_SMTP_USER,
+			password: config.ALARM_SMTP_PASSWORD,
+			to:       splitList(config.ALARM_SMTP_TO),
+		}
+	}
+	if config.ALARM_WEBHOOK_URL != "" {
+		notifiers["webhook"] = newWebhookNotifier(config.ALARM_WEBHOOK_TYPE, config.ALARM_WEBHOOK_URL)
+	}
+	if config.ALARM_SMS_URL != "" && config.ALARM_SMS_MOBILES != "" {
+		notifiers["sms"] = newSmsNotifier(config.ALARM_SMS_URL, config.ALARM_SMS_KEY, splitList(config.ALARM_SMS_MOBILES))
+	}
+	return notifiers
+}
+
+// 解析告警等级与通道的对应关系，如 EMAIL:email;PANIC:email,webhook
+func parseRoutes(routes string) map[string][]string {
+	result := map[string][]string{}
+	for _, route := range strings.Split(routes, ";") {
+		level, channels, ok := strings.Cut(route, ":")
+		if !ok {
+			continue
+		}
+		result[strings.TrimSpace(level)] = splitList(channels)
+	}
+	return result
+}
+
+func splitList(str string) []string {
+	var result []string
+	for _, item := range strings.Split(str, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,104 @@
+package alarm
+
+import (
+	"GinDemo/config"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type job struct {
+	channel  string
+	notifier Notifier
+	info     ErrorInfo
+}
+
+var (
+	queue     chan job
+	routes    map[string][]string
+	notifiers map[string]Notifier
+	startOnce sync.Once
+	pending   sync.WaitGroup
+
+	// 调用 Wait 后不再接收新的告警，保证 pending.Add 不与 pending.Wait 并发
+	mu     sync.Mutex
+	closed bool
+
+	// 重试的退避基数
+	retryBackoff = time.Second
+)
+
+// 启动发送协程
+func start() {
+	routes = parseRoutes(config.ALARM_ROUTES)
+	notifiers = newNotifiers()
+	queue = make(chan job, config.ALARM_QUEUE_SIZE)
+	for i := 0; i < config.ALARM_WORKERS; i++ {
+		go worker()
+	}
+}
+
+// 按等级路由到对应通道，异步发送，队列满或已停止接收时丢弃
+func dispatch(info ErrorInfo) {
+	startOnce.Do(start)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if closed {
+		fmt.Println("alarm queue is closed, drop:", info.Alarm, info.Message)
+		return
+	}
+	for _, channel := range routes[info.Alarm] {
+		notifier, ok := notifiers[channel]
+		if !ok {
+			continue
+		}
+		pending.Add(1)
+		select {
+		case queue <- job{channel, notifier, info}:
+		default:
+			pending.Done()
+			fmt.Println("alarm queue is full, drop:", channel, info.Message)
+		}
+	}
+}
+
+func worker() {
+	for j := range queue {
+		send(j)
+		pending.Done()
+	}
+}
+
+// 发送失败时按 1s、2s、4s... 退避重试
+func send(j job) {
+	var err error
+	for attempt := 0; attempt <= config.ALARM_RETRY; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(1<<(attempt-1)) * retryBackoff)
+		}
+		if err = j.notifier.Send(j.info); err == nil {
+			return
+		}
+	}
+	fmt.Println("alarm send failed:", j.channel, err)
+}
+
+// Wait 停止接收新的告警并等待队列中的告警发送完成，超时返回 false，程序退出前调用
+func Wait(timeout time.Duration) bool {
+	mu.Lock()
+	closed = true
+	mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
@@ -0,0 +1,97 @@
+package alarm
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyNotifier 前 failures 次发送失败，之后交给 next 发送
+type flakyNotifier struct {
+	failures int32
+	attempts int32
+	next     Notifier
+}
+
+func (n *flakyNotifier) Send(info ErrorInfo) error {
+	if atomic.AddInt32(&n.attempts, 1) <= n.failures {
+		return errors.New("temporary failure")
+	}
+	return n.next.Send(info)
+}
+
+func readAlarms(t *testing.T, filename string) []map[string]interface{} {
+	t.Helper()
+	file, err := os.Open(filename)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var alarms []map[string]interface{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var alarm map[string]interface{}
+		if err = json.Unmarshal(scanner.Bytes(), &alarm); err != nil {
+			t.Fatal(err)
+		}
+		alarms = append(alarms, alarm)
+	}
+	return alarms
+}
+
+func TestDispatchRetryAndWait(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "logs", "alarm.log")
+	email := &flakyNotifier{failures: 2, next: &fileNotifier{channel: "email", filename: filename}}
+	sms := &flakyNotifier{failures: 100, next: &fileNotifier{channel: "sms", filename: filename}}
+
+	// 使用测试通道启动队列，替代按配置创建的通道
+	retryBackoff = time.Millisecond
+	startOnce.Do(func() {
+		routes = map[string][]string{"EMAIL": {"email"}, "SMS": {"sms"}}
+		notifiers = map[string]Notifier{"email": email, "sms": sms}
+		queue = make(chan job, 10)
+		go worker()
+	})
+
+	_ = Email("db connect failed, mobile 13812345678")
+	_ = Sms("sms gateway down")
+	if !Wait(5 * time.Second) {
+		t.Fatal("Wait timed out before the queue is drained")
+	}
+
+	// email 重试两次后写入文件，sms 超过重试次数后放弃
+	if got := atomic.LoadInt32(&email.attempts); got != 3 {
+		t.Errorf("email attempts = %d, want 3", got)
+	}
+	if got := atomic.LoadInt32(&sms.attempts); got != 4 {
+		t.Errorf("sms attempts = %d, want 4", got)
+	}
+	alarms := readAlarms(t, filename)
+	if len(alarms) != 1 {
+		t.Fatalf("got %d alarms, want 1: %v", len(alarms), alarms)
+	}
+	if alarms[0]["channel"] != "email" || alarms[0]["alarm"] != "EMAIL" {
+		t.Errorf("unexpected alarm: %v", alarms[0])
+	}
+	if alarms[0]["message"] != "db connect failed, mobile ******" {
+		t.Errorf("message is not redacted: %v", alarms[0]["message"])
+	}
+
+	// Wait 之后不再接收新的告警
+	_ = Email("after wait")
+	if !Wait(time.Second) {
+		t.Fatal("Wait timed out after the queue is closed")
+	}
+	if got := atomic.LoadInt32(&email.attempts); got != 3 {
+		t.Errorf("email attempts after Wait = %d, want 3", got)
+	}
+}
@@ -0,0 +1,48 @@
+package alarm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// smsNotifier 通过 HTTP 短信网关发送告警短信
+type smsNotifier struct {
+	url     string
+	key     string
+	mobiles []string
+	client  *http.Client
+}
+
+func newSmsNotifier(url string, key string, mobiles []string) *smsNotifier {
+	return &smsNotifier{url: url, key: key, mobiles: mobiles, client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+func (n *smsNotifier) Send(info ErrorInfo) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"mobiles": n.mobiles,
+		"content": "[" + info.Alarm + "] " + firstLine(info.Message),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", n.key)
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("sms gateway responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
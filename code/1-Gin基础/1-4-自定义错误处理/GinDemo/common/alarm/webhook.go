@@ -0,0 +1,61 @@
+package alarm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// webhookNotifier 机器人 webhook，支持钉钉、企业微信、Slack 的消息格式
+type webhookNotifier struct {
+	kind   string
+	url    string
+	client *http.Client
+}
+
+func newWebhookNotifier(kind string, url string) *webhookNotifier {
+	return &webhookNotifier{kind: kind, url: url, client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+func (n *webhookNotifier) Send(info ErrorInfo) error {
+	var payload interface{}
+	switch n.kind {
+	case "slack":
+		payload = map[string]string{"text": content(info)}
+	default: // dingtalk、wecom 使用相同的文本消息格式
+		payload = map[string]interface{}{
+			"msgtype": "text",
+			"text":    map[string]string{"content": content(info)},
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	// 钉钉、企业微信通过 errcode 返回发送结果
+	if n.kind != "slack" {
+		var result struct {
+			ErrCode int    `json:"errcode"`
+			ErrMsg  string `json:"errmsg"`
+		}
+		if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return err
+		}
+		if result.ErrCode != 0 {
+			return fmt.Errorf("webhook error %d: %s", result.ErrCode, result.ErrMsg)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package redact
+
+import (
+	"GinDemo/config"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const mask = "******"
+
+// 身份证号、手机号、Bearer 令牌
+var patterns = []*regexp.Regexp{
+	regexp.MustCompile(`\b\d{17}[\dXx]\b`),
+	regexp.MustCompile(`\b1[3-9]\d{9}\b`),
+	regexp.MustCompile(`(?i)\bbearer\s+[\w\-.~+/]+=*`),
+}
+
+// 字段名是否在 REDACT_FIELDS 中，不区分大小写
+func sensitive(key string) bool {
+	return strings.Contains(","+config.REDACT_FIELDS+",", ","+strings.ToLower(key)+",")
+}
+
+// String 替换字符串中的身份证号、手机号及令牌
+func String(s string) string {
+	for _, pattern := range patterns {
+		s = pattern.ReplaceAllString(s, mask)
+	}
+	return s
+}
+
+// Values 脱敏表单、查询参数
+func Values(values url.Values) url.Values {
+	result := url.Values{}
+	for key, items := range values {
+		for _, item := range items {
+			if sensitive(key) {
+				item = mask
+			}
+			result.Add(key, String(item))
+		}
+	}
+	return result
+}
+
+// Data 递归脱敏 json 解析后的数据
+func Data(data interface{}) interface{} {
+	switch v := data.(type) {
+	case string:
+		return String(v)
+	case map[string]interface{}:
+		result := map[string]interface{}{}
+		for key, item := range v {
+			if sensitive(key) && item != nil {
+				result[key] = mask
+			} else {
+				result[key] = Data(item)
+			}
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = Data(item)
+		}
+		return result
+	}
+	return data
+}
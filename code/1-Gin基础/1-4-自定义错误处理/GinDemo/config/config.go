@@ -6,6 +6,36 @@ const (
 	APP_SECRET = "6YJSuc50uJ18zj45"
 	API_EXPIRY = "120"
 
+	SHUTDOWN_TIMEOUT = 5 // 退出时等待请求处理及告警发送完成的最长时间，秒
+
 	LOG_FILE_PATH = "/Users/tianjiangyu/MyStudy/Go-learning/A2-The-Way-To-Gin/code/1-Gin基础/1-4-自定义错误处理/logs"
 	LOG_FILE_NAME = "system.log"
+
+	// 日志脱敏字段，多个字段用逗号分隔，不区分大小写
+	REDACT_FIELDS = "password,mobile,phone,authorization,token,access_token,secret,id_card,sn"
+
+	// 告警模式：local 写入本地文件（开发、测试使用），remote 发送到真实通道
+	ALARM_MODE       = "local"
+	ALARM_LOCAL_FILE = "logs/alarm.log" // 相对于运行目录，目录不存在时自动创建
+	ALARM_QUEUE_SIZE = 1000             // 告警队列长度，队列满时丢弃
+	ALARM_WORKERS    = 2                // 发送协程数
+	ALARM_RETRY      = 3                // 发送失败重试次数
+	// 告警等级与通道的对应关系，格式：等级:通道1,通道2;等级:通道
+	ALARM_ROUTES = "EMAIL:email;SMS:sms;WX:webhook;PANIC:email,webhook"
+
+	// 邮件通道
+	ALARM_SMTP_HOST     = "smtp.example.com"
+	ALARM_SMTP_PORT     = "465"
+	ALARM_SMTP_USER     = "alarm@example.com"
+	ALARM_SMTP_PASSWORD = ""
+	ALARM_SMTP_TO       = "dev@example.com" // 多个收件人用逗号分隔
+
+	// webhook 通道，类型可选 dingtalk / wecom / slack
+	ALARM_WEBHOOK_TYPE = "dingtalk"
+	ALARM_WEBHOOK_URL  = ""
+
+	// 短信通道，通过 HTTP 短信网关发送
+	ALARM_SMS_URL     = ""
+	ALARM_SMS_KEY     = ""
+	ALARM_SMS_MOBILES = "" // 多个手机号用逗号分隔
 )
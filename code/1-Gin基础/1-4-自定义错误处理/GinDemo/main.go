@@ -1,18 +1,44 @@
 package main
 
 import (
+	"GinDemo/common/alarm"
 	"GinDemo/config"
 	"GinDemo/router"
+	"context"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
 func main() {
 	gin.SetMode(gin.ReleaseMode) // 默认为 debug 模式，设置为发布模式
 	engine := gin.Default()
 	router.InitRouter(engine) // 设置路由
-	err := engine.Run(config.PORT)
-	if err != nil {
+
+	server := &http.Server{Addr: config.PORT, Handler: engine}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			fmt.Println(err.Error())
+			os.Exit(1)
+		}
+	}()
+
+	// 收到退出信号后停止接收请求，并等待队列中的告警发送完成
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	timeout := config.SHUTDOWN_TIMEOUT * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
 		fmt.Println(err.Error())
 	}
+	if !alarm.Wait(timeout) {
+		fmt.Println("alarm queue is not drained before exit")
+	}
 }
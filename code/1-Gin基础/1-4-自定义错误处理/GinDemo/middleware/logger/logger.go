@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"GinDemo/common/redact"
 	"GinDemo/config"
 	"GinDemo/entity"
 	"bytes"
@@ -91,8 +92,8 @@ func LoggerToFile() gin.HandlerFunc {
 			err := json.Unmarshal([]byte(responseBody), &res)
 			if err == nil {
 				responseCode = strconv.Itoa(res.Code)
-				responseMsg = res.Message
-				responseData = res.Data
+				responseMsg = redact.String(res.Message)
+				responseData = redact.Data(res.Data)
 			}
 		}
 
@@ -105,11 +106,11 @@ func LoggerToFile() gin.HandlerFunc {
 		// 日志格式 - 更详细的记录模式
 		logger.WithFields(logrus.Fields{
 			"request_method":    c.Request.Method,
-			"request_uri":       c.Request.RequestURI,
+			"request_uri":       requestUri(c),
 			"request_proto":     c.Request.Proto,
 			"request_useragent": c.Request.UserAgent(),
 			"request_referer":   c.Request.Referer(),
-			"request_post_data": c.Request.PostForm.Encode(),
+			"request_post_data": redact.Values(c.Request.PostForm).Encode(),
 			"request_client_ip": c.ClientIP(),
 
 			"response_status_code": c.Writer.Status(),
@@ -121,3 +122,11 @@ func LoggerToFile() gin.HandlerFunc {
 		}).Info()
 	}
 }
+
+// 脱敏后的请求 uri
+func requestUri(c *gin.Context) string {
+	if c.Request.URL.RawQuery == "" {
+		return c.Request.URL.Path
+	}
+	return c.Request.URL.Path + "?" + redact.Values(c.Request.URL.Query()).Encode()
+}
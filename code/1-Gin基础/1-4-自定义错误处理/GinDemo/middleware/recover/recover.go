@@ -2,8 +2,10 @@ package recover
 
 import (
 	"GinDemo/common/alarm"
+	"GinDemo/entity"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"net/http"
 )
 
 func Recover() gin.HandlerFunc {
@@ -11,6 +13,13 @@ func Recover() gin.HandlerFunc {
 		defer func() {
 			if r := recover(); r != nil {
 				alarm.Panic(fmt.Sprintf("%s", r))
+
+				// 返回统一错误响应，不向客户端暴露 panic 信息
+				res := entity.Result{}
+				res.SetCode(entity.CODE_ERROR)
+				res.SetMessage("系统异常，请稍后重试")
+				c.JSON(http.StatusInternalServerError, res)
+				c.Abort()
 			}
 		}()
 		c.Next()
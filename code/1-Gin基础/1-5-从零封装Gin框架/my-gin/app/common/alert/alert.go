@@ -0,0 +1,230 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+// Alert 告警内容
+type Alert struct {
+	Level   string            `json:"level"`
+	Title   string            `json:"title"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields"`
+	Time    time.Time         `json:"time"`
+	Repeat  int               `json:"repeat"` // 去重窗口内被合并的次数
+}
+
+// Notifier 告警通道
+type Notifier interface {
+	Notify(alert Alert) error
+}
+
+// 未配置时的队列长度、发送协程数及重试退避基数
+const (
+	defaultQueueSize    = 100
+	defaultWorkers      = 2
+	defaultRetryBackoff = time.Second
+)
+
+// Options 告警分发配置
+type Options struct {
+	DedupWindow  time.Duration
+	MaxPerMinute int
+	QueueSize    int           // 队列长度，队列满时丢弃
+	Workers      int           // 发送协程数
+	Retry        int           // 发送失败重试次数
+	RetryBackoff time.Duration // 重试退避基数，按 1、2、4... 倍递增
+}
+
+// Dispatcher 告警分发，相同 key 的告警在去重窗口内只发送一次，并限制每分钟发送总数
+// 告警进入有界队列，由固定数量的协程发送，各通道失败时分别退避重试
+type Dispatcher struct {
+	notifiers []Notifier
+	opts      Options
+	logger    *zap.Logger
+	queue     chan job
+	pending   sync.WaitGroup
+
+	mu          sync.Mutex
+	closed      bool // 调用 Wait 后不再接收新的告警，保证 pending.Add 不与 pending.Wait 并发
+	lastSent    map[string]time.Time
+	suppressed  map[string]*suppression
+	windowStart time.Time
+	windowCount int
+}
+
+// 单个通道的发送任务
+type job struct {
+	notifier Notifier
+	alert    Alert
+}
+
+// 被去重或限流的告警次数及最后一次被拦截的时间
+type suppression struct {
+	count int
+	last  time.Time
+}
+
+func NewDispatcher(logger *zap.Logger, opts Options, notifiers ...Notifier) *Dispatcher {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = defaultQueueSize
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = defaultWorkers
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = defaultRetryBackoff
+	}
+	d := &Dispatcher{
+		notifiers:  notifiers,
+		opts:       opts,
+		logger:     logger,
+		queue:      make(chan job, opts.QueueSize),
+		lastSent:   map[string]time.Time{},
+		suppressed: map[string]*suppression{},
+	}
+	for i := 0; i < opts.Workers; i++ {
+		go d.worker()
+	}
+	return d
+}
+
+// Fire 异步发送告警，key 用于去重
+func (d *Dispatcher) Fire(key string, alert Alert) {
+	if d == nil || len(d.notifiers) == 0 {
+		return
+	}
+	if alert.Time.IsZero() {
+		alert.Time = time.Now()
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		d.logger.Warn("alert dispatcher is closed, drop", zap.String("key", key), zap.String("title", alert.Title))
+		return
+	}
+	repeat, ok := d.allow(key, alert.Time)
+	if !ok {
+		return
+	}
+	alert.Repeat = repeat
+
+	for _, notifier := range d.notifiers {
+		d.pending.Add(1)
+		select {
+		case d.queue <- job{notifier, alert}:
+		default:
+			d.pending.Done()
+			d.logger.Warn("alert queue is full, drop", zap.String("notifier", notifierName(notifier)), zap.String("key", key))
+		}
+	}
+}
+
+func (d *Dispatcher) worker() {
+	for j := range d.queue {
+		d.send(j)
+		d.pending.Done()
+	}
+}
+
+// 发送失败时按 RetryBackoff 的 1、2、4... 倍退避重试
+func (d *Dispatcher) send(j job) {
+	var err error
+	for attempt := 0; attempt <= d.opts.Retry; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(1<<(attempt-1)) * d.opts.RetryBackoff)
+		}
+		if err = j.notifier.Notify(j.alert); err == nil {
+			return
+		}
+	}
+	d.logger.Error("alert notify failed", zap.String("notifier", notifierName(j.notifier)), zap.Int("attempts", d.opts.Retry+1), zap.Any("err", err))
+}
+
+func notifierName(notifier Notifier) string {
+	return fmt.Sprintf("%T", notifier)
+}
+
+// Wait 停止接收新的告警并等待队列中的告警发送完成，用于关闭前避免丢失告警
+func (d *Dispatcher) Wait(ctx context.Context) error {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	d.closed = true
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// 去重及限流判断，返回被合并的告警次数，需持有 d.mu
+func (d *Dispatcher) allow(key string, now time.Time) (int, bool) {
+	if last, ok := d.lastSent[key]; ok && now.Sub(last) < d.opts.DedupWindow {
+		d.suppress(key, now)
+		return 0, false
+	}
+
+	if now.Sub(d.windowStart) >= time.Minute {
+		d.windowStart = now
+		d.windowCount = 0
+	}
+	if d.opts.MaxPerMinute > 0 && d.windowCount >= d.opts.MaxPerMinute {
+		d.suppress(key, now)
+		return 0, false
+	}
+	d.windowCount++
+
+	repeat := 0
+	if s, ok := d.suppressed[key]; ok {
+		repeat = s.count
+		delete(d.suppressed, key)
+	}
+	d.evict(now)
+	d.lastSent[key] = now
+	return repeat, true
+}
+
+func (d *Dispatcher) suppress(key string, now time.Time) {
+	s, ok := d.suppressed[key]
+	if !ok {
+		s = &suppression{}
+		d.suppressed[key] = s
+	}
+	s.count++
+	s.last = now
+}
+
+// 按时间清理过期的去重及拦截记录，避免不再出现的告警一直占用内存
+// 拦截记录在去重窗口及限流窗口内均未再出现时清理，被丢弃的合并次数写入日志
+func (d *Dispatcher) evict(now time.Time) {
+	for k, last := range d.lastSent {
+		if now.Sub(last) >= d.opts.DedupWindow {
+			delete(d.lastSent, k)
+		}
+	}
+	ttl := d.opts.DedupWindow
+	if ttl < time.Minute {
+		ttl = time.Minute
+	}
+	for k, s := range d.suppressed {
+		if now.Sub(s.last) >= ttl {
+			d.logger.Warn("alert suppression expired", zap.String("key", k), zap.Int("pending", s.count), zap.Time("last", s.last))
+			delete(d.suppressed, k)
+		}
+	}
+}
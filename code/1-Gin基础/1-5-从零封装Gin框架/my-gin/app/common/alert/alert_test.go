@@ -0,0 +1,112 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyNotifier 前 failures 次发送失败
+type flakyNotifier struct {
+	failures int
+
+	mu       sync.Mutex
+	attempts int
+	sent     []Alert
+}
+
+func (n *flakyNotifier) Notify(alert Alert) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.attempts++
+	if n.attempts <= n.failures {
+		return errors.New("temporary failure")
+	}
+	n.sent = append(n.sent, alert)
+	return nil
+}
+
+func (n *flakyNotifier) result() (int, int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.attempts, len(n.sent)
+}
+
+func TestDispatcherRetryAndWait(t *testing.T) {
+	webhook := &flakyNotifier{failures: 2}
+	broken := &flakyNotifier{failures: 100}
+	d := NewDispatcher(zap.NewNop(), Options{DedupWindow: time.Minute, Retry: 3, RetryBackoff: time.Millisecond}, webhook, broken)
+
+	d.Fire("panic|a", Alert{Title: "a"})
+	d.Fire("panic|a", Alert{Title: "a"}) // 去重窗口内合并
+	d.Fire("panic|b", Alert{Title: "b"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := d.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// webhook 重试两次后两条告警均发送成功，broken 每条告警各尝试 4 次后放弃
+	if attempts, sent := webhook.result(); attempts != 4 || sent != 2 {
+		t.Errorf("webhook attempts = %d, sent = %d, want 4, 2", attempts, sent)
+	}
+	if attempts, sent := broken.result(); attempts != 8 || sent != 0 {
+		t.Errorf("broken attempts = %d, sent = %d, want 8, 0", attempts, sent)
+	}
+
+	// Wait 之后不再接收新的告警
+	d.Fire("panic|c", Alert{Title: "c"})
+	if err := d.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if attempts, _ := webhook.result(); attempts != 4 {
+		t.Errorf("webhook attempts after Wait = %d, want 4", attempts)
+	}
+}
+
+func TestDispatcherQueueFull(t *testing.T) {
+	block := make(chan struct{})
+	notifier := &blockingNotifier{block: block}
+	d := NewDispatcher(zap.NewNop(), Options{QueueSize: 1, Workers: 1}, notifier)
+
+	// 第一条被协程取走阻塞，第二条进入队列，其余丢弃
+	for _, key := range []string{"a", "b", "c", "d"} {
+		d.Fire(key, Alert{Title: key})
+		time.Sleep(10 * time.Millisecond)
+	}
+	close(block)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := d.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := notifier.count(); got != 2 {
+		t.Errorf("sent %d alerts, want 2", got)
+	}
+}
+
+type blockingNotifier struct {
+	block chan struct{}
+
+	mu   sync.Mutex
+	sent int
+}
+
+func (n *blockingNotifier) Notify(alert Alert) error {
+	<-n.block
+	n.mu.Lock()
+	n.sent++
+	n.mu.Unlock()
+	return nil
+}
+
+func (n *blockingNotifier) count() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.sent
+}
@@ -0,0 +1,50 @@
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+// LogNotifier 将告警写入日志
+type LogNotifier struct {
+	Logger *zap.Logger
+}
+
+func (n LogNotifier) Notify(alert Alert) error {
+	fields := []zap.Field{zap.String("level", alert.Level), zap.String("message", alert.Message), zap.Int("repeat", alert.Repeat)}
+	for k, v := range alert.Fields {
+		fields = append(fields, zap.String(k, v))
+	}
+	n.Logger.Warn("alert: "+alert.Title, fields...)
+	return nil
+}
+
+// WebhookNotifier 以 json 格式将告警 POST 到指定地址
+type WebhookNotifier struct {
+	Url    string
+	Client *http.Client
+}
+
+func NewWebhookNotifier(url string) WebhookNotifier {
+	return WebhookNotifier{Url: url, Client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+func (n WebhookNotifier) Notify(alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	resp, err := n.Client.Post(n.Url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
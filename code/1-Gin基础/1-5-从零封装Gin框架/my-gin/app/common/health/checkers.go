@@ -0,0 +1,71 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+	"os"
+	"path/filepath"
+)
+
+// DBChecker 检查数据库连接
+type DBChecker struct {
+	DB *gorm.DB
+}
+
+func (DBChecker) Name() string {
+	return "db"
+}
+
+func (c DBChecker) Check(ctx context.Context) error {
+	if c.DB == nil {
+		return errors.New("database is not initialized")
+	}
+	sqlDB, err := c.DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// RedisChecker 检查 Redis 连接
+type RedisChecker struct {
+	Client *redis.Client
+}
+
+func (RedisChecker) Name() string {
+	return "redis"
+}
+
+func (c RedisChecker) Check(ctx context.Context) error {
+	if c.Client == nil {
+		return errors.New("redis is not initialized")
+	}
+	return c.Client.Ping(ctx).Err()
+}
+
+// DiskChecker 检查本地存储目录可写
+type DiskChecker struct {
+	RootDir string
+}
+
+func (DiskChecker) Name() string {
+	return "storage"
+}
+
+func (c DiskChecker) Check(ctx context.Context) error {
+	file, err := os.CreateTemp(c.RootDir, ".health-*")
+	if err != nil {
+		return err
+	}
+	name := file.Name()
+	_, err = file.WriteString("ok")
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if removeErr := os.Remove(filepath.Clean(name)); err == nil {
+		err = removeErr
+	}
+	return err
+}
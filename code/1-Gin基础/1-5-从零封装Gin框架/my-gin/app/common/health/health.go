@@ -0,0 +1,135 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusOk   = "ok"
+	StatusFail = "fail"
+)
+
+// Checker 健康检查项
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+// CheckResult 单项检查结果
+type CheckResult struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Report 检查报告
+type Report struct {
+	Status    string                 `json:"status"`
+	Checks    map[string]CheckResult `json:"checks,omitempty"`
+	CheckedAt time.Time              `json:"checked_at"`
+}
+
+type entry struct {
+	checker Checker
+	timeout time.Duration
+}
+
+// Registry 管理检查项，缓存检查结果，并在优雅关闭开始后直接返回失败
+type Registry struct {
+	cacheTTL     time.Duration
+	shuttingDown atomic.Bool
+
+	mu       sync.Mutex
+	entries  []entry
+	cached   *Report
+	cachedAt time.Time
+}
+
+func NewRegistry(cacheTTL time.Duration) *Registry {
+	return &Registry{cacheTTL: cacheTTL}
+}
+
+// Register 注册检查项，timeout 为该项的超时时间
+func (r *Registry) Register(checker Checker, timeout time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry{checker, timeout})
+	r.cached = nil
+}
+
+// SetShuttingDown 标记开始优雅关闭，之后就绪检查始终失败
+func (r *Registry) SetShuttingDown() {
+	r.shuttingDown.Store(true)
+}
+
+// Ready 执行全部检查项，在缓存有效期内直接返回上次结果
+func (r *Registry) Ready(ctx context.Context) Report {
+	if r.shuttingDown.Load() {
+		return Report{Status: StatusFail, Checks: map[string]CheckResult{
+			"shutdown": {Status: StatusFail, Error: "server is shutting down"},
+		}, CheckedAt: time.Now()}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cached != nil && time.Since(r.cachedAt) < r.cacheTTL {
+		return *r.cached
+	}
+
+	// 检查结果会被缓存，不受当前请求取消的影响
+	report := r.run(context.WithoutCancel(ctx))
+	r.cached = &report
+	r.cachedAt = report.CheckedAt
+	return report
+}
+
+// 并发执行检查项
+func (r *Registry) run(ctx context.Context) Report {
+	report := Report{Status: StatusOk, Checks: make(map[string]CheckResult, len(r.entries)), CheckedAt: time.Now()}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, e := range r.entries {
+		wg.Add(1)
+		go func(e entry) {
+			defer wg.Done()
+			result := check(ctx, e)
+			mu.Lock()
+			report.Checks[e.checker.Name()] = result
+			if result.Status != StatusOk {
+				report.Status = StatusFail
+			}
+			mu.Unlock()
+		}(e)
+	}
+	wg.Wait()
+	return report
+}
+
+func check(ctx context.Context, e entry) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- e.checker.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := CheckResult{Status: StatusOk, Duration: time.Since(start).String()}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
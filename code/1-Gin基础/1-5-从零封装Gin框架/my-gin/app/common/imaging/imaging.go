@@ -0,0 +1,216 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/HugoSmits86/nativewebp"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+// 缩略图缩放方式
+const (
+	FitContain = "contain" // 等比缩放至不超过目标宽高
+	FitCover   = "cover"   // 等比缩放后居中裁剪为目标宽高
+)
+
+// 缩略图输出格式
+const (
+	FormatJpeg = "jpeg"
+	FormatPng  = "png"
+	FormatWebp = "webp" // 无损压缩，不支持设置质量
+)
+
+const (
+	// DefaultQuality 未配置时的 jpeg 压缩质量
+	DefaultQuality = 85
+	// 按 EXIF 方向旋转后重新编码原图时使用的 jpeg 压缩质量
+	reencodeQuality = 95
+	// MaxPixels 允许解码的最大像素数，解码前按图片头部信息校验，避免超大尺寸图片耗尽内存
+	MaxPixels = 40_000_000
+)
+
+// ErrTooLarge 图片像素数超过 MaxPixels
+var ErrTooLarge = errors.New("imaging: image is too large")
+
+var formats = map[string]struct {
+	mimeType  string
+	extension string
+}{
+	FormatJpeg: {"image/jpeg", ".jpg"},
+	FormatPng:  {"image/png", ".png"},
+	FormatWebp: {"image/webp", ".webp"},
+}
+
+// Supported 是否支持处理该类型的图片
+func Supported(mimeType string) bool {
+	switch mimeType {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+		return true
+	}
+	return false
+}
+
+// MimeType 输出格式对应的 MIME 类型
+func MimeType(format string) string {
+	return formats[format].mimeType
+}
+
+// Extension 输出格式对应的文件后缀
+func Extension(format string) string {
+	return formats[format].extension
+}
+
+// Sanitize 去除图片元数据，EXIF 方向不为 1 时先按方向旋转再重新编码，
+// 避免去除方向信息后图片显示方向错误
+func Sanitize(data []byte) ([]byte, error) {
+	orientation := Orientation(data)
+	if orientation == 1 {
+		return Strip(data)
+	}
+
+	img, format, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	switch format {
+	case "jpeg":
+		err = Encode(&buf, orient(img, orientation), FormatJpeg, reencodeQuality)
+	case "png":
+		err = Encode(&buf, orient(img, orientation), FormatPng, 0)
+	default:
+		return Strip(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode 解码图片，并按 EXIF 方向旋转，GIF 仅解码第一帧
+func Decode(data []byte) (image.Image, error) {
+	img, _, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	return orient(img, Orientation(data)), nil
+}
+
+// 先读取图片尺寸，像素数超过 MaxPixels 时不解码
+func decode(data []byte) (image.Image, string, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	if config.Width <= 0 || config.Height <= 0 || int64(config.Width)*int64(config.Height) > MaxPixels {
+		return nil, "", ErrTooLarge
+	}
+	return image.Decode(bytes.NewReader(data))
+}
+
+// Resize 按目标宽高缩放图片，宽或高为 0 时按另一边等比缩放，不放大图片
+func Resize(img image.Image, width int, height int, fit string) image.Image {
+	bounds := img.Bounds()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+	if width == 0 {
+		width = max(srcWidth*height/srcHeight, 1)
+	}
+	if height == 0 {
+		height = max(srcHeight*width/srcWidth, 1)
+	}
+
+	src := bounds
+	var dstWidth, dstHeight int
+	if fit == FitCover {
+		// 按目标宽高比从中间裁剪，宽高比悬殊时裁剪区域至少保留 1 像素
+		cropWidth, cropHeight := srcWidth, max(srcWidth*height/width, 1)
+		if cropHeight > srcHeight {
+			cropWidth, cropHeight = max(srcHeight*width/height, 1), srcHeight
+		}
+		x, y := bounds.Min.X+(srcWidth-cropWidth)/2, bounds.Min.Y+(srcHeight-cropHeight)/2
+		src = image.Rect(x, y, x+cropWidth, y+cropHeight)
+		dstWidth, dstHeight = min(width, cropWidth), min(height, cropHeight)
+	} else {
+		scale := min(float64(width)/float64(srcWidth), float64(height)/float64(srcHeight), 1)
+		dstWidth, dstHeight = max(int(float64(srcWidth)*scale+0.5), 1), max(int(float64(srcHeight)*scale+0.5), 1)
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, src, draw.Src, nil)
+	return dst
+}
+
+// Encode 按格式编码图片，quality 仅对 jpeg 有效，jpeg 不支持透明，透明区域填充为白色
+func Encode(w io.Writer, img image.Image, format string, quality int) error {
+	switch format {
+	case FormatJpeg:
+		if quality <= 0 {
+			quality = DefaultQuality
+		}
+		return jpeg.Encode(w, flatten(img), &jpeg.Options{Quality: quality})
+	case FormatPng:
+		return png.Encode(w, img)
+	case FormatWebp:
+		return nativewebp.Encode(w, img, nil)
+	}
+	return fmt.Errorf("imaging: unsupported format %q", format)
+}
+
+func flatten(img image.Image) image.Image {
+	if opaque, ok := img.(interface{ Opaque() bool }); ok && opaque.Opaque() {
+		return img
+	}
+	dst := image.NewRGBA(img.Bounds())
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, img.Bounds().Min, draw.Over)
+	return dst
+}
+
+// 按 EXIF 方向旋转、翻转图片，使其按正常方向显示
+func orient(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	src := image.NewNRGBA(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy()))
+	draw.Draw(src, src.Bounds(), img, img.Bounds().Min, draw.Src)
+	width, height := src.Bounds().Dx(), src.Bounds().Dy()
+
+	dstBounds := image.Rect(0, 0, width, height)
+	if orientation >= 5 {
+		// 5-8 需要旋转 90 度，宽高互换
+		dstBounds = image.Rect(0, 0, height, width)
+	}
+	dst := image.NewNRGBA(dstBounds)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // 水平翻转
+				dx, dy = width-1-x, y
+			case 3: // 旋转 180 度
+				dx, dy = width-1-x, height-1-y
+			case 4: // 垂直翻转
+				dx, dy = x, height-1-y
+			case 5: // 沿左上-右下对角线翻转
+				dx, dy = y, x
+			case 6: // 顺时针旋转 90 度
+				dx, dy = height-1-y, x
+			case 7: // 沿右上-左下对角线翻转
+				dx, dy = height-1-y, width-1-x
+			case 8: // 逆时针旋转 90 度
+				dx, dy = y, width-1-x
+			}
+			copy(dst.Pix[dst.PixOffset(dx, dy):dst.PixOffset(dx, dy)+4], src.Pix[src.PixOffset(x, y):src.PixOffset(x, y)+4])
+		}
+	}
+	return dst
+}
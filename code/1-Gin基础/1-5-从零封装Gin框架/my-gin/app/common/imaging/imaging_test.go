@@ -0,0 +1,159 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+func newImage(width, height int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = 0xFF
+	}
+	return img
+}
+
+func TestResize(t *testing.T) {
+	tests := []struct {
+		name          string
+		srcW, srcH    int
+		width, height int
+		fit           string
+		wantW, wantH  int
+	}{
+		{"contain landscape", 400, 200, 100, 100, FitContain, 100, 50},
+		{"contain portrait", 200, 400, 100, 100, FitContain, 50, 100},
+		{"contain no upscale", 50, 40, 100, 100, FitContain, 50, 40},
+		{"contain width only", 400, 200, 100, 0, FitContain, 100, 50},
+		{"contain height only", 400, 200, 0, 50, FitContain, 100, 50},
+		{"cover square", 400, 200, 100, 100, FitCover, 100, 100},
+		{"cover wide target", 400, 400, 200, 100, FitCover, 200, 100},
+		{"cover no upscale", 80, 60, 100, 100, FitCover, 60, 60},
+		{"cover extreme tall target", 1000, 1, 1, 1000, FitCover, 1, 1},
+		{"cover extreme wide target", 1, 1000, 1000, 1, FitCover, 1, 1},
+		{"cover thin source", 3000, 2, 100, 100, FitCover, 2, 2},
+		{"contain extreme source", 10000, 1, 100, 100, FitContain, 100, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := Resize(newImage(tt.srcW, tt.srcH), tt.width, tt.height, tt.fit)
+			if w, h := dst.Bounds().Dx(), dst.Bounds().Dy(); w != tt.wantW || h != tt.wantH {
+				t.Errorf("got %dx%d, want %dx%d", w, h, tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// 3x2 的图片，左上角为红色，右上角为绿色
+	red := color.NRGBA{R: 0xFF, A: 0xFF}
+	green := color.NRGBA{G: 0xFF, A: 0xFF}
+	src := newImage(3, 2)
+	src.SetNRGBA(0, 0, red)
+	src.SetNRGBA(2, 0, green)
+
+	tests := []struct {
+		orientation   int
+		width, height int
+		red, green    image.Point
+	}{
+		{1, 3, 2, image.Pt(0, 0), image.Pt(2, 0)},
+		{2, 3, 2, image.Pt(2, 0), image.Pt(0, 0)},
+		{3, 3, 2, image.Pt(2, 1), image.Pt(0, 1)},
+		{4, 3, 2, image.Pt(0, 1), image.Pt(2, 1)},
+		{5, 2, 3, image.Pt(0, 0), image.Pt(0, 2)},
+		{6, 2, 3, image.Pt(1, 0), image.Pt(1, 2)},
+		{7, 2, 3, image.Pt(1, 2), image.Pt(1, 0)},
+		{8, 2, 3, image.Pt(0, 2), image.Pt(0, 0)},
+	}
+	for _, tt := range tests {
+		dst := orient(src, tt.orientation)
+		if w, h := dst.Bounds().Dx(), dst.Bounds().Dy(); w != tt.width || h != tt.height {
+			t.Errorf("orientation %d: got %dx%d, want %dx%d", tt.orientation, w, h, tt.width, tt.height)
+			continue
+		}
+		if got := color.NRGBAModel.Convert(dst.At(tt.red.X, tt.red.Y)); got != red {
+			t.Errorf("orientation %d: pixel at %v is %v, want red", tt.orientation, tt.red, got)
+		}
+		if got := color.NRGBAModel.Convert(dst.At(tt.green.X, tt.green.Y)); got != green {
+			t.Errorf("orientation %d: pixel at %v is %v, want green", tt.orientation, tt.green, got)
+		}
+	}
+}
+
+// 生成指定尺寸的 jpeg，orientation 大于 0 时在 SOI 后插入带方向标签的 EXIF 段
+func newJpeg(t *testing.T, width, height int, orientation uint16) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, newImage(width, height), nil); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if orientation == 0 {
+		return data
+	}
+
+	// TIFF 头 + IFD0（1 个 Orientation 标签）
+	var tiff bytes.Buffer
+	tiff.WriteString("MM\x00\x2a")
+	for _, v := range []interface{}{
+		uint32(8), uint16(1),
+		uint16(0x0112), uint16(3), uint32(1), orientation, uint16(0),
+		uint32(0),
+	} {
+		if err := binary.Write(&tiff, binary.BigEndian, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	segment := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+
+	result := append([]byte{}, jpegSoi...)
+	result = append(result, 0xFF, 0xE1)
+	result = binary.BigEndian.AppendUint16(result, uint16(len(segment)+2))
+	result = append(result, segment...)
+	return append(result, data[len(jpegSoi):]...)
+}
+
+func TestSanitizeJpeg(t *testing.T) {
+	tests := []struct {
+		name          string
+		orientation   uint16
+		width, height int
+	}{
+		{"without exif", 0, 4, 2},
+		{"exif orientation 1", 1, 4, 2},
+		{"exif orientation 3", 3, 4, 2},
+		{"exif orientation 6", 6, 2, 4},
+		{"exif orientation 8", 8, 2, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := newJpeg(t, 4, 2, tt.orientation)
+			if tt.orientation > 0 && Orientation(data) != int(tt.orientation) {
+				t.Fatalf("Orientation = %d, want %d", Orientation(data), tt.orientation)
+			}
+
+			sanitized, err := Sanitize(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Contains(sanitized, exifHeader) {
+				t.Error("exif is not removed")
+			}
+			if Orientation(sanitized) != 1 {
+				t.Errorf("Orientation after sanitize = %d, want 1", Orientation(sanitized))
+			}
+			config, err := jpeg.DecodeConfig(bytes.NewReader(sanitized))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if config.Width != tt.width || config.Height != tt.height {
+				t.Errorf("got %dx%d, want %dx%d", config.Width, config.Height, tt.width, tt.height)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+var (
+	jpegSoi      = []byte{0xFF, 0xD8}
+	pngSignature = []byte("\x89PNG\r\n\x1a\n")
+	exifHeader   = []byte("Exif\x00\x00")
+	riffHeader   = []byte("RIFF")
+	webpHeader   = []byte("WEBP")
+)
+
+var errInvalidImage = errors.New("imaging: invalid image data")
+
+// JPEG 中需去除的段：APP1（EXIF、XMP）、APP13（IPTC）、COM（注释）
+// APP0（JFIF）、APP2（ICC 色彩配置）、APP14（Adobe 色彩转换）影响显示效果，予以保留
+var jpegStripMarkers = map[byte]bool{0xE1: true, 0xED: true, 0xFE: true}
+
+// PNG 中需去除的块：EXIF、文本及修改时间
+var pngStripChunks = map[string]bool{"eXIf": true, "tEXt": true, "zTXt": true, "iTXt": true, "tIME": true}
+
+// WebP 中需去除的块：EXIF、XMP，同时清除 VP8X 中对应的标记位
+var webpStripChunks = map[string]byte{"EXIF": 0x08, "XMP ": 0x04}
+
+// Strip 去除 JPEG、PNG、WebP 中的 EXIF（含 GPS）、XMP 及文本等元数据，不重新编码图像数据
+// 其他格式原样返回
+func Strip(data []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(data, jpegSoi):
+		return stripJpeg(data)
+	case bytes.HasPrefix(data, pngSignature):
+		return stripPng(data)
+	case len(data) >= 12 && bytes.HasPrefix(data, riffHeader) && bytes.Equal(data[8:12], webpHeader):
+		return stripWebp(data)
+	}
+	return data, nil
+}
+
+// Orientation 读取 EXIF 中的图片方向，取值 1-8，无方向信息时返回 1
+func Orientation(data []byte) int {
+	var exif []byte
+	switch {
+	case bytes.HasPrefix(data, jpegSoi):
+		_, _ = walkJpeg(data, func(marker byte, segment []byte) bool {
+			if marker == 0xE1 && bytes.HasPrefix(segment, exifHeader) {
+				exif = segment[len(exifHeader):]
+				return false
+			}
+			return true
+		})
+	case bytes.HasPrefix(data, pngSignature):
+		_ = walkPng(data, func(chunkType string, chunk []byte) bool {
+			if chunkType == "eXIf" {
+				exif = chunk
+				return false
+			}
+			return true
+		})
+	}
+	return tiffOrientation(exif)
+}
+
+func stripJpeg(data []byte) ([]byte, error) {
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(jpegSoi)
+	scan, err := walkJpeg(data, func(marker byte, segment []byte) bool {
+		if jpegStripMarkers[marker] {
+			return true
+		}
+		out.Write([]byte{0xFF, marker})
+		_ = binary.Write(out, binary.BigEndian, uint16(len(segment)+2))
+		out.Write(segment)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	// 图像数据从 SOS 段开始，原样保留
+	out.Write(data[scan:])
+	return out.Bytes(), nil
+}
+
+// 遍历 SOS 之前的 JPEG 段，返回 SOS 段的位置，fn 返回 false 时停止
+func walkJpeg(data []byte, fn func(marker byte, segment []byte) bool) (int, error) {
+	offset := len(jpegSoi)
+	for offset+4 <= len(data) {
+		if data[offset] != 0xFF {
+			return 0, errInvalidImage
+		}
+		marker := data[offset+1]
+		// 段之间允许填充 0xFF
+		if marker == 0xFF {
+			offset++
+			continue
+		}
+		if marker == 0xDA {
+			return offset, nil
+		}
+		length := int(binary.BigEndian.Uint16(data[offset+2:]))
+		if length < 2 || offset+2+length > len(data) {
+			return 0, errInvalidImage
+		}
+		if !fn(marker, data[offset+4:offset+2+length]) {
+			return offset, nil
+		}
+		offset += 2 + length
+	}
+	return 0, errInvalidImage
+}
+
+func stripPng(data []byte) ([]byte, error) {
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(pngSignature)
+	offset := len(pngSignature)
+	err := walkPng(data, func(chunkType string, chunk []byte) bool {
+		// 块结构：长度(4) 类型(4) 数据 CRC(4)
+		size := 12 + len(chunk)
+		if !pngStripChunks[chunkType] {
+			out.Write(data[offset : offset+size])
+		}
+		offset += size
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// 遍历 PNG 数据块，fn 返回 false 时停止
+func walkPng(data []byte, fn func(chunkType string, chunk []byte) bool) error {
+	offset := len(pngSignature)
+	for offset+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[offset:]))
+		if offset+12+length > len(data) {
+			return errInvalidImage
+		}
+		chunkType := string(data[offset+4 : offset+8])
+		if !fn(chunkType, data[offset+8:offset+8+length]) || chunkType == "IEND" {
+			return nil
+		}
+		offset += 12 + length
+	}
+	return errInvalidImage
+}
+
+func stripWebp(data []byte) ([]byte, error) {
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:12])
+	var flags byte
+	offset := 12
+	for offset+8 <= len(data) {
+		// 块结构：类型(4) 长度(4，小端) 数据，长度为奇数时补齐 1 字节
+		chunkType := string(data[offset : offset+4])
+		size := 8 + int(binary.LittleEndian.Uint32(data[offset+4:]))
+		size += size % 2
+		if offset+size > len(data) {
+			return nil, errInvalidImage
+		}
+		if flag, ok := webpStripChunks[chunkType]; ok {
+			flags |= flag
+		} else {
+			out.Write(data[offset : offset+size])
+		}
+		offset += size
+	}
+
+	result := out.Bytes()
+	binary.LittleEndian.PutUint32(result[4:], uint32(len(result)-8))
+	if len(result) > 20 && string(result[12:16]) == "VP8X" {
+		result[20] &^= flags
+	}
+	return result, nil
+}
+
+// 从 TIFF 结构的 IFD0 中读取 Orientation(0x0112) 标签
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			if orientation := int(order.Uint16(tiff[entry+8:])); orientation >= 1 && orientation <= 8 {
+				return orientation
+			}
+			break
+		}
+	}
+	return 1
+}
@@ -0,0 +1,167 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+// Hook 组件的启动及关闭钩子
+type Hook struct {
+	Name      string
+	DependsOn []string      // 依赖的组件，先于本组件启动、晚于本组件关闭
+	Timeout   time.Duration // 单个钩子的超时时间，为 0 时使用默认值
+	OnStart   func(ctx context.Context) error
+	OnStop    func(ctx context.Context) error
+}
+
+// Lifecycle 按依赖顺序启动组件，并按相反顺序关闭已启动的组件
+type Lifecycle struct {
+	logger         *zap.Logger
+	defaultTimeout time.Duration
+
+	mu      sync.Mutex
+	hooks   []Hook
+	started []Hook
+}
+
+func New(logger *zap.Logger, defaultTimeout time.Duration) *Lifecycle {
+	return &Lifecycle{logger: logger, defaultTimeout: defaultTimeout}
+}
+
+// Append 注册组件钩子，需在 Start 前调用
+func (l *Lifecycle) Append(hook Hook) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hooks = append(l.hooks, hook)
+}
+
+// Start 依次执行 OnStart，任一失败时关闭已启动的组件并返回错误
+func (l *Lifecycle) Start(ctx context.Context) error {
+	l.mu.Lock()
+	hooks, err := sortHooks(l.hooks)
+	l.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range hooks {
+		if hook.OnStart != nil {
+			if err := l.run(ctx, hook, "start", hook.OnStart); err != nil {
+				return errors.Join(fmt.Errorf("start %s: %w", hook.Name, err), l.Stop(ctx))
+			}
+		}
+		l.mu.Lock()
+		l.started = append(l.started, hook)
+		l.mu.Unlock()
+	}
+	return nil
+}
+
+// Stop 逆序执行已启动组件的 OnStop，单个失败不影响其余组件，返回全部错误
+func (l *Lifecycle) Stop(ctx context.Context) error {
+	l.mu.Lock()
+	started := l.started
+	l.started = nil
+	l.mu.Unlock()
+
+	var errs []error
+	for i := len(started) - 1; i >= 0; i-- {
+		hook := started[i]
+		if hook.OnStop == nil {
+			continue
+		}
+		if err := l.run(ctx, hook, "stop", hook.OnStop); err != nil {
+			errs = append(errs, fmt.Errorf("stop %s: %w", hook.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// 在超时时间内执行钩子，钩子 panic 时转为错误
+func (l *Lifecycle) run(ctx context.Context, hook Hook, phase string, fn func(ctx context.Context) error) error {
+	timeout := hook.Timeout
+	if timeout <= 0 {
+		timeout = l.defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("timeout after %s: %w", timeout, ctx.Err())
+	}
+
+	fields := []zap.Field{zap.String("component", hook.Name), zap.String("phase", phase), zap.Duration("duration", time.Since(start))}
+	if err != nil {
+		l.logger.Error("lifecycle hook failed", append(fields, zap.Error(err))...)
+		return err
+	}
+	l.logger.Info("lifecycle hook done", fields...)
+	return nil
+}
+
+// 按依赖关系排序，无依赖关系的组件保持注册顺序
+func sortHooks(hooks []Hook) ([]Hook, error) {
+	index := make(map[string]int, len(hooks))
+	for i, hook := range hooks {
+		if _, ok := index[hook.Name]; ok {
+			return nil, fmt.Errorf("lifecycle: duplicate component %q", hook.Name)
+		}
+		index[hook.Name] = i
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(hooks))
+	sorted := make([]Hook, 0, len(hooks))
+
+	var visit func(i int, path []string) error
+	visit = func(i int, path []string) error {
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("lifecycle: dependency cycle %v", append(path, hooks[i].Name))
+		}
+		state[i] = visiting
+		for _, dep := range hooks[i].DependsOn {
+			j, ok := index[dep]
+			if !ok {
+				return fmt.Errorf("lifecycle: %q depends on unknown component %q", hooks[i].Name, dep)
+			}
+			if err := visit(j, append(path, hooks[i].Name)); err != nil {
+				return err
+			}
+		}
+		state[i] = visited
+		sorted = append(sorted, hooks[i])
+		return nil
+	}
+
+	for i := range hooks {
+		if err := visit(i, nil); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
@@ -0,0 +1,112 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func hook(name string, dependsOn ...string) Hook {
+	return Hook{Name: name, DependsOn: dependsOn}
+}
+
+func hookNames(hooks []Hook) []string {
+	names := make([]string, len(hooks))
+	for i, hook := range hooks {
+		names[i] = hook.Name
+	}
+	return names
+}
+
+func TestSortHooks(t *testing.T) {
+	tests := []struct {
+		name    string
+		hooks   []Hook
+		want    []string
+		wantErr string
+	}{
+		{
+			name:  "registration order",
+			hooks: []Hook{hook("a"), hook("b"), hook("c")},
+			want:  []string{"a", "b", "c"},
+		},
+		{
+			name:  "dependency first",
+			hooks: []Hook{hook("orphan_cleaner", "db", "redis"), hook("db", "trace"), hook("trace"), hook("redis", "trace")},
+			want:  []string{"trace", "db", "redis", "orphan_cleaner"},
+		},
+		{
+			name:    "cycle",
+			hooks:   []Hook{hook("a", "b"), hook("b", "c"), hook("c", "a")},
+			wantErr: "dependency cycle [a b c a]",
+		},
+		{
+			name:    "self dependency",
+			hooks:   []Hook{hook("a", "a")},
+			wantErr: "dependency cycle [a a]",
+		},
+		{
+			name:    "unknown dependency",
+			hooks:   []Hook{hook("a"), hook("b", "missing")},
+			wantErr: `"b" depends on unknown component "missing"`,
+		},
+		{
+			name:    "duplicate",
+			hooks:   []Hook{hook("db"), hook("redis"), hook("db")},
+			wantErr: `duplicate component "db"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorted, err := sortHooks(tt.hooks)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hookNames(sorted); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStartFailureStopsStarted(t *testing.T) {
+	var stopped []string
+	lc := New(zap.NewNop(), time.Second)
+	for _, name := range []string{"a", "b"} {
+		name := name
+		lc.Append(Hook{
+			Name:    name,
+			OnStart: func(ctx context.Context) error { return nil },
+			OnStop: func(ctx context.Context) error {
+				stopped = append(stopped, name)
+				return nil
+			},
+		})
+	}
+	failure := errors.New("connect failed")
+	lc.Append(Hook{
+		Name:    "c",
+		OnStart: func(ctx context.Context) error { return failure },
+		OnStop: func(ctx context.Context) error {
+			stopped = append(stopped, "c")
+			return nil
+		},
+	})
+
+	if err := lc.Start(context.Background()); !errors.Is(err, failure) {
+		t.Fatalf("got error %v, want %v", err, failure)
+	}
+	if want := []string{"b", "a"}; !reflect.DeepEqual(stopped, want) {
+		t.Errorf("stopped %v, want %v", stopped, want)
+	}
+}
@@ -0,0 +1,25 @@
+package request
+
+type LogLevel struct {
+	Module string `form:"module" json:"module"`
+	Level  string `form:"level" json:"level" binding:"omitempty,oneof=debug info warn error dpanic panic fatal"`
+}
+
+func (logLevel LogLevel) GetMessages() ValidatorMessages {
+	return ValidatorMessages{
+		"level.oneof": "日志等级不正确",
+	}
+}
+
+type DebugUser struct {
+	UserId  string `form:"user_id" json:"user_id" binding:"required"`
+	Minutes int    `form:"minutes" json:"minutes" binding:"omitempty,gt=0,lte=1440"`
+}
+
+func (debugUser DebugUser) GetMessages() ValidatorMessages {
+	return ValidatorMessages{
+		"user_id.required": "用户 ID 不能为空",
+		"minutes.gt":       "调试时长必须大于 0",
+		"minutes.lte":      "调试时长不能超过 1440 分钟",
+	}
+}
@@ -0,0 +1,44 @@
+package request
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	RequestIdHeader = "X-Request-ID" // 请求 ID 请求头 / 响应头
+	RequestIdKey    = "request_id"   // 请求 ID 在 gin.Context 中的 key
+	LoggerKey       = "logger"       // 请求级 logger 在 gin.Context 中的 key
+	UserIdKey       = "id"           // JWTAuth 中间件写入的用户 ID
+)
+
+// GetRequestId 获取当前请求的请求 ID
+func GetRequestId(c *gin.Context) string {
+	return c.GetString(RequestIdKey)
+}
+
+// GetUserId 获取当前登录用户 ID，未登录时为空
+func GetUserId(c *gin.Context) string {
+	return c.GetString(UserIdKey)
+}
+
+// SetUserId 记录当前登录用户，并为请求级 logger 附加用户信息
+func SetUserId(c *gin.Context, userId string) {
+	c.Set(UserIdKey, userId)
+	SetLogger(c, Logger(c).With(zap.String("user_id", userId)))
+}
+
+// SetLogger 替换请求级 logger
+func SetLogger(c *gin.Context, logger *zap.Logger) {
+	c.Set(LoggerKey, logger)
+}
+
+// Logger 获取请求级 logger，未经过 RequestId 中间件时不输出日志
+func Logger(c *gin.Context) *zap.Logger {
+	if l, exists := c.Get(LoggerKey); exists {
+		if logger, ok := l.(*zap.Logger); ok {
+			return logger
+		}
+	}
+	return zap.NewNop()
+}
@@ -0,0 +1,31 @@
+package request
+
+// 未传 page_size 时的每页数量
+const defaultPageSize = 20
+
+// Pagination 分页参数，page 从 1 开始
+type Pagination struct {
+	Page     int `form:"page" json:"page" binding:"omitempty,gte=1"`
+	PageSize int `form:"page_size" json:"page_size" binding:"omitempty,gte=1,lte=100"`
+}
+
+// GetPage 当前页码，未传时为第 1 页
+func (pagination Pagination) GetPage() int {
+	if pagination.Page < 1 {
+		return 1
+	}
+	return pagination.Page
+}
+
+// GetPageSize 每页数量，未传时为 20
+func (pagination Pagination) GetPageSize() int {
+	if pagination.PageSize < 1 {
+		return defaultPageSize
+	}
+	return pagination.PageSize
+}
+
+// Offset 查询偏移量
+func (pagination Pagination) Offset() int {
+	return (pagination.GetPage() - 1) * pagination.GetPageSize()
+}
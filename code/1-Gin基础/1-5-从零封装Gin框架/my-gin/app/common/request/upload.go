@@ -3,13 +3,74 @@ package request
 import "mime/multipart"
 
 type ImageUpload struct {
-	Business string                `form:"business" json:"business" binding:"required"`
-	Image    *multipart.FileHeader `form:"image" json:"image" binding:"required"`
+	Business   string                `form:"business" json:"business" binding:"required"`
+	Image      *multipart.FileHeader `form:"image" json:"image" binding:"required"`
+	Visibility string                `form:"visibility" json:"visibility" binding:"omitempty,oneof=public private"`
 }
 
 func (imageUpload ImageUpload) GetMessages() ValidatorMessages {
 	return ValidatorMessages{
 		"business.required": "业务类型不能为空",
 		"image.required":    "请选择图片",
+		"visibility.oneof":  "可见性只能为 public 或 private",
+	}
+}
+
+type FileUpload struct {
+	Business   string                `form:"business" json:"business" binding:"required"`
+	File       *multipart.FileHeader `form:"file" json:"file" binding:"required"`
+	Visibility string                `form:"visibility" json:"visibility" binding:"omitempty,oneof=public private"`
+}
+
+func (fileUpload FileUpload) GetMessages() ValidatorMessages {
+	return ValidatorMessages{
+		"business.required": "业务类型不能为空",
+		"file.required":     "请选择文件",
+		"visibility.oneof":  "可见性只能为 public 或 private",
+	}
+}
+
+type MediaUrl struct {
+	Variant     string `form:"variant" json:"variant"`
+	Disposition string `form:"disposition" json:"disposition" binding:"omitempty,oneof=inline attachment"`
+}
+
+func (mediaUrl MediaUrl) GetMessages() ValidatorMessages {
+	return ValidatorMessages{
+		"disposition.oneof": "disposition 只能为 inline 或 attachment",
+	}
+}
+
+type UploadInit struct {
+	Business   string `form:"business" json:"business" binding:"required"`
+	Filename   string `form:"filename" json:"filename" binding:"required"`
+	Size       int64  `form:"size" json:"size" binding:"required,gt=0"`
+	Checksum   string `form:"checksum" json:"checksum" binding:"required,len=64,hexadecimal"`
+	Visibility string `form:"visibility" json:"visibility" binding:"omitempty,oneof=public private"`
+}
+
+func (uploadInit UploadInit) GetMessages() ValidatorMessages {
+	return ValidatorMessages{
+		"business.required":    "业务类型不能为空",
+		"filename.required":    "文件名不能为空",
+		"size.required":        "文件大小不能为空",
+		"size.gt":              "文件大小必须大于 0",
+		"checksum.required":    "文件 sha256 不能为空",
+		"checksum.len":         "文件 sha256 格式不正确",
+		"checksum.hexadecimal": "文件 sha256 格式不正确",
+		"visibility.oneof":     "可见性只能为 public 或 private",
+	}
+}
+
+type MediaList struct {
+	Pagination
+	Business string `form:"business" json:"business"`
+}
+
+func (mediaList MediaList) GetMessages() ValidatorMessages {
+	return ValidatorMessages{
+		"page.gte":      "页码必须大于 0",
+		"page_size.gte": "每页数量必须大于 0",
+		"page_size.lte": "每页数量不能超过 100",
 	}
 }
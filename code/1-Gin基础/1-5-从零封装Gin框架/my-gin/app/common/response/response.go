@@ -1,70 +1,141 @@
 package response
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
+	"my-gin/app/common/request"
+	"my-gin/config"
 	"my-gin/global"
 	"net/http"
-	"os"
 )
 
-// Response 响应结构体
+// FormatKey 错误响应格式在 gin.Context 中的 key，由 ErrorHandler 中间件按 app.error_format 写入，未写入时使用 legacy 格式
+const FormatKey = "error_format"
+
+// Response 响应结构体，错误响应带有错误标识及请求 ID，错误附加信息放在 Data 中
 type Response struct {
 	ErrorCode int         `json:"error_code"`
+	ErrorKey  string      `json:"error_key,omitempty"`
 	Data      interface{} `json:"data"`
 	Message   string      `json:"message"`
+	RequestId string      `json:"request_id,omitempty"`
+}
+
+// Problem RFC 7807 错误响应，app.error_format 为 problem 时使用
+type Problem struct {
+	Type      string                 `json:"type"`
+	Title     string                 `json:"title"`
+	Status    int                    `json:"status"`
+	Detail    string                 `json:"detail,omitempty"`
+	Instance  string                 `json:"instance,omitempty"`
+	ErrorCode int                    `json:"error_code"`
+	ErrorKey  string                 `json:"error_key,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	RequestId string                 `json:"request_id,omitempty"`
+}
+
+const problemContentType = "application/problem+json; charset=utf-8"
+
+// PageData 分页数据
+type PageData struct {
+	List     interface{} `json:"list"`
+	Total    int64       `json:"total"`
+	Page     int         `json:"page"`
+	PageSize int         `json:"page_size"`
 }
 
 // Success 响应成功 ErrorCode 为 0 表示成功
 func Success(c *gin.Context, data interface{}) {
 	c.JSON(http.StatusOK, Response{
-		0,
-		data,
-		"ok",
+		ErrorCode: 0,
+		Data:      data,
+		Message:   "ok",
 	})
 }
 
-// Fail 响应失败 ErrorCode 不为 0 表示失败
-func Fail(c *gin.Context, errorCode int, msg string) {
-	c.JSON(http.StatusOK, Response{
-		errorCode,
-		nil,
-		msg,
+// Fail 响应失败 ErrorCode 不为 0 表示失败，按 app.error_format 输出 legacy 或 problem+json 格式
+func Fail(c *gin.Context, error global.CustomError) {
+	requestId := request.GetRequestId(c)
+	if c.GetString(FormatKey) != config.ErrorFormatProblem {
+		var data interface{}
+		if len(error.Details) > 0 {
+			data = error.Details
+		}
+		c.JSON(http.StatusOK, Response{
+			ErrorCode: error.ErrorCode,
+			ErrorKey:  error.Key,
+			Data:      data,
+			Message:   error.ErrorMsg,
+			RequestId: requestId,
+		})
+		return
+	}
+
+	status := error.HttpStatus
+	if status == 0 {
+		status = http.StatusBadRequest
+	}
+	c.Header("Content-Type", problemContentType)
+	c.JSON(status, Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    error.ErrorMsg,
+		Instance:  c.Request.URL.Path,
+		ErrorCode: error.ErrorCode,
+		ErrorKey:  error.Key,
+		Details:   error.Details,
+		RequestId: requestId,
 	})
 }
 
-// FailByError 失败响应，返回自定义错误的错误码、错误信息
-func FailByError(c *gin.Context, error global.CustomError) {
-	Fail(c, error.ErrorCode, error.ErrorMsg)
+// FailByError 失败响应，自定义错误返回对应的错误码及 HTTP 状态码，
+// 其他错误视为服务器内部错误，不向客户端输出错误信息
+func FailByError(c *gin.Context, err error) {
+	Fail(c, AsCustomError(err))
+}
+
+// AsCustomError 取出错误链中的自定义错误，没有时作为服务器内部错误并记录原始错误
+func AsCustomError(err error) global.CustomError {
+	var customError global.CustomError
+	if errors.As(err, &customError) {
+		return customError
+	}
+	return global.Errors.ServerError.WithCause(err)
 }
 
 // ValidateFail 请求参数验证失败
 func ValidateFail(c *gin.Context, msg string) {
-	Fail(c, global.Errors.ValidateError.ErrorCode, msg)
+	Fail(c, global.Errors.ValidateError.WithMsg(msg))
 }
 
 // BusinessFail 业务逻辑失败
 func BusinessFail(c *gin.Context, msg string) {
-	Fail(c, global.Errors.BusinessError.ErrorCode, msg)
+	Fail(c, global.Errors.BusinessError.WithMsg(msg))
 }
 
 func TokenFail(c *gin.Context) {
-	FailByError(c, global.Errors.TokenError)
+	Fail(c, global.Errors.TokenError)
 }
 
-// ServerError 添加 ServerError() 方法，作为 RecoveryFunc
-func ServerError(c *gin.Context, err interface{}) {
-	msg := "Internal Server Error"
-	// 非生产环境显示具体错误, 生产环境不适应大幅度写入日志，会浪费性能
-	if global.App.Config.App.Env != "production" && os.Getenv(gin.EnvGinMode) != gin.ReleaseMode {
-		if _, ok := err.(error); ok {
-			msg = err.(error).Error()
-		}
+// ServerError 服务器内部错误响应，由 Recovery 中间件调用，detail 为空时使用通用错误信息
+func ServerError(c *gin.Context, detail string) {
+	serverError := global.Errors.ServerError
+	if detail != "" {
+		serverError = serverError.WithMsg(detail)
 	}
 
-	c.JSON(http.StatusInternalServerError, Response{
-		ErrorCode: http.StatusInternalServerError,
-		Data:      nil,
-		Message:   msg,
-	})
+	// legacy 格式同样返回 500
+	if c.GetString(FormatKey) != config.ErrorFormatProblem {
+		c.JSON(http.StatusInternalServerError, Response{
+			ErrorCode: serverError.ErrorCode,
+			ErrorKey:  serverError.Key,
+			Data:      nil,
+			Message:   serverError.ErrorMsg,
+			RequestId: request.GetRequestId(c),
+		})
+	} else {
+		Fail(c, serverError)
+	}
 	c.Abort()
 }
@@ -0,0 +1,62 @@
+package local
+
+import (
+	"errors"
+	golocal "github.com/jassue/go-storage/local"
+	"github.com/jassue/go-storage/storage"
+	"io/fs"
+	"my-gin/app/common/storage/signed"
+	"net/url"
+	"path/filepath"
+	"time"
+)
+
+// local 在 go-storage 本地驱动基础上，通过签名下载接口提供临时访问链接
+type local struct {
+	storage.Storage
+	signer  *signed.Signer
+	rootDir string
+}
+
+// Init 初始化本地驱动并替换 go-storage 注册的驱动
+func Init(config golocal.Config, signer *signed.Signer) (signed.Storage, error) {
+	disk, err := golocal.Init(config)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &local{Storage: disk, signer: signer, rootDir: config.RootDir}
+	storage.Register(storage.Local, l)
+	return l, nil
+}
+
+// TemporaryUrl 生成不绑定用户的签名下载链接，需绑定用户时由调用方通过 signer 签发
+func (l *local) TemporaryUrl(key string, ttl time.Duration) (string, error) {
+	return l.signer.Sign(url.Values{
+		signed.ParamDisk: {string(storage.Local)},
+		signed.ParamKey:  {key},
+	}, ttl), nil
+}
+
+// Walk 遍历 prefix 目录下的文件，key 为相对根目录的路径
+func (l *local) Walk(prefix string, fn func(key string, modTime time.Time) error) error {
+	root := filepath.Join(l.rootDir, filepath.FromSlash(prefix))
+	err := filepath.WalkDir(root, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		key, err := filepath.Rel(l.rootDir, name)
+		if err != nil {
+			return err
+		}
+		return fn(filepath.ToSlash(key), info.ModTime())
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
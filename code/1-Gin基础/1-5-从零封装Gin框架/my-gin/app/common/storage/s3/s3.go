@@ -0,0 +1,223 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jassue/go-storage/storage"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"io"
+	"mime"
+	"my-gin/app/common/storage/signed"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// DiskName S3 兼容存储（AWS S3、MinIO、Ceph 等）驱动名称
+const DiskName storage.DiskName = "s3"
+
+// 初始化时检查存储桶的超时时间
+const initTimeout = 10 * time.Second
+
+type Config struct {
+	Endpoint  string `mapstructure:"endpoint" json:"endpoint" yaml:"endpoint" validate:"omitempty,url"`
+	Bucket    string `mapstructure:"bucket" json:"bucket" yaml:"bucket" validate:"required_with=Endpoint"`
+	Region    string `mapstructure:"region" json:"region" yaml:"region"`
+	PathStyle bool   `mapstructure:"path_style" json:"path_style" yaml:"path_style"`
+	AccessKey string `mapstructure:"access_key" json:"access_key" yaml:"access_key"`
+	SecretKey string `mapstructure:"secret_key" json:"secret_key" yaml:"secret_key"`
+	BaseUrl   string `mapstructure:"base_url" json:"base_url" yaml:"base_url" validate:"omitempty,url"`
+}
+
+type s3 struct {
+	config  *Config
+	client  *minio.Client
+	baseUrl string
+}
+
+// Init 创建客户端并检查存储桶，存储桶不存在或无法访问时返回错误，成功后注册为 s3 驱动
+func Init(config Config) (signed.Storage, error) {
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("s3: invalid endpoint %q, expect http(s)://host[:port]", config.Endpoint)
+	}
+	if config.Bucket == "" {
+		return nil, errors.New("s3: bucket is required")
+	}
+
+	bucketLookup := minio.BucketLookupDNS
+	if config.PathStyle {
+		bucketLookup = minio.BucketLookupPath
+	}
+	client, err := minio.New(endpoint.Host, &minio.Options{
+		Creds:        credentials.NewStaticV4(config.AccessKey, config.SecretKey, ""),
+		Secure:       endpoint.Scheme == "https",
+		Region:       config.Region,
+		BucketLookup: bucketLookup,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("s3: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), initTimeout)
+	defer cancel()
+	exists, err := client.BucketExists(ctx, config.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("s3: check bucket %q: %w", config.Bucket, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("s3: bucket %q does not exist", config.Bucket)
+	}
+
+	s := &s3{
+		config:  &config,
+		client:  client,
+		baseUrl: baseUrl(config, endpoint),
+	}
+	storage.Register(DiskName, s)
+	return s, nil
+}
+
+// 未配置 base_url 时按寻址方式拼接：path-style 为 endpoint/bucket，否则为 bucket.endpoint
+func baseUrl(config Config, endpoint *url.URL) string {
+	if config.BaseUrl != "" {
+		return strings.TrimRight(config.BaseUrl, "/")
+	}
+	if config.PathStyle {
+		return endpoint.Scheme + "://" + endpoint.Host + "/" + config.Bucket
+	}
+	return endpoint.Scheme + "://" + config.Bucket + "." + endpoint.Host
+}
+
+func (s *s3) getKey(key string) string {
+	return strings.TrimPrefix(storage.NormalizeKey(key), "/")
+}
+
+func (s *s3) Put(key string, r io.Reader, dataLength int64) error {
+	key = s.getKey(key)
+	_, err := s.client.PutObject(context.Background(), s.config.Bucket, key, r, dataLength, minio.PutObjectOptions{
+		ContentType: contentType(key),
+	})
+	return toStorageErr(err)
+}
+
+// PutFile 上传本地文件，localFile 为本地文件路径
+func (s *s3) PutFile(key string, localFile string) error {
+	key = s.getKey(key)
+	_, err := s.client.FPutObject(context.Background(), s.config.Bucket, key, localFile, minio.PutObjectOptions{
+		ContentType: contentType(key),
+	})
+	return toStorageErr(err)
+}
+
+func (s *s3) Get(key string) (io.ReadCloser, error) {
+	object, err := s.client.GetObject(context.Background(), s.config.Bucket, s.getKey(key), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, toStorageErr(err)
+	}
+	// GetObject 在首次读取时才发起请求，先 Stat 以便文件不存在时立即返回错误
+	if _, err = object.Stat(); err != nil {
+		_ = object.Close()
+		return nil, toStorageErr(err)
+	}
+	return object, nil
+}
+
+func (s *s3) Rename(srcKey string, destKey string) error {
+	if err := s.Copy(srcKey, destKey); err != nil {
+		return err
+	}
+	return s.Delete(srcKey)
+}
+
+func (s *s3) Copy(srcKey string, destKey string) error {
+	_, err := s.client.CopyObject(context.Background(),
+		minio.CopyDestOptions{Bucket: s.config.Bucket, Object: s.getKey(destKey)},
+		minio.CopySrcOptions{Bucket: s.config.Bucket, Object: s.getKey(srcKey)},
+	)
+	return toStorageErr(err)
+}
+
+func (s *s3) Exists(key string) (bool, error) {
+	_, err := s.stat(key)
+	if errors.Is(err, storage.FileNotFoundErr) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *s3) Size(key string) (int64, error) {
+	info, err := s.stat(key)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size, nil
+}
+
+func (s *s3) Delete(key string) error {
+	err := s.client.RemoveObject(context.Background(), s.config.Bucket, s.getKey(key), minio.RemoveObjectOptions{})
+	return toStorageErr(err)
+}
+
+func (s *s3) Url(key string) string {
+	return s.baseUrl + "/" + s.getKey(key)
+}
+
+// TemporaryUrl 生成预签名链接，签名包含 endpoint，不使用 base_url
+func (s *s3) TemporaryUrl(key string, ttl time.Duration) (string, error) {
+	u, err := s.client.PresignedGetObject(context.Background(), s.config.Bucket, s.getKey(key), ttl, nil)
+	if err != nil {
+		return "", toStorageErr(err)
+	}
+	return u.String(), nil
+}
+
+// Walk 遍历 prefix 下的对象
+func (s *s3) Walk(prefix string, fn func(key string, modTime time.Time) error) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for object := range s.client.ListObjects(ctx, s.config.Bucket, minio.ListObjectsOptions{Prefix: s.getKey(prefix), Recursive: true}) {
+		if object.Err != nil {
+			return toStorageErr(object.Err)
+		}
+		if err := fn(object.Key, object.LastModified); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *s3) stat(key string) (minio.ObjectInfo, error) {
+	info, err := s.client.StatObject(context.Background(), s.config.Bucket, s.getKey(key), minio.StatObjectOptions{})
+	return info, toStorageErr(err)
+}
+
+// 按扩展名设置 Content-Type，便于浏览器直接展示
+func contentType(key string) string {
+	if t := mime.TypeByExtension(path.Ext(key)); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// 转换为 storage 包中定义的错误，与本地驱动保持一致
+func toStorageErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	resp := minio.ToErrorResponse(err)
+	switch {
+	case resp.Code == "NoSuchKey" || resp.StatusCode == http.StatusNotFound && resp.Code != "NoSuchBucket":
+		return storage.FileNotFoundErr
+	case resp.Code == "AccessDenied" || resp.StatusCode == http.StatusForbidden:
+		return storage.FileNoPermissionErr
+	}
+	return err
+}
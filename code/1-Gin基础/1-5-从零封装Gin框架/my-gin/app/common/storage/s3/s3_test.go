@@ -0,0 +1,221 @@
+package s3
+
+import (
+	"errors"
+	"github.com/jassue/go-storage/storage"
+	"github.com/minio/minio-go/v7"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 集成测试需要 S3 兼容服务，如本地 MinIO：
+// S3_TEST_ENDPOINT=http://127.0.0.1:9000 S3_TEST_BUCKET=test S3_TEST_ACCESS_KEY=minioadmin S3_TEST_SECRET_KEY=minioadmin go test ./app/common/storage/s3
+// 未设置 S3_TEST_ENDPOINT 时跳过，存储桶需提前创建
+func testConfig(t *testing.T) Config {
+	t.Helper()
+	endpoint := os.Getenv("S3_TEST_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("S3_TEST_ENDPOINT is not set")
+	}
+	bucket := os.Getenv("S3_TEST_BUCKET")
+	if bucket == "" {
+		bucket = "test"
+	}
+	return Config{
+		Endpoint:  endpoint,
+		Bucket:    bucket,
+		Region:    os.Getenv("S3_TEST_REGION"),
+		PathStyle: true,
+		AccessKey: os.Getenv("S3_TEST_ACCESS_KEY"),
+		SecretKey: os.Getenv("S3_TEST_SECRET_KEY"),
+	}
+}
+
+func newTestDisk(t *testing.T) (*s3, string) {
+	t.Helper()
+	disk, err := Init(testConfig(t))
+	if err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	// 每次运行使用独立前缀，结束后清理
+	prefix := "s3-test/" + strconv.FormatInt(time.Now().UnixNano(), 10) + "/"
+	s := disk.(*s3)
+	t.Cleanup(func() {
+		_ = s.Walk(prefix, func(key string, modTime time.Time) error {
+			return s.Delete(key)
+		})
+	})
+	return s, prefix
+}
+
+func put(t *testing.T, s *s3, key string, content string) {
+	t.Helper()
+	if err := s.Put(key, strings.NewReader(content), int64(len(content))); err != nil {
+		t.Fatalf("Put(%s) error = %v", key, err)
+	}
+}
+
+func read(t *testing.T, s *s3, key string) string {
+	t.Helper()
+	r, err := s.Get(key)
+	if err != nil {
+		t.Fatalf("Get(%s) error = %v", key, err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("read %s error = %v", key, err)
+	}
+	return string(data)
+}
+
+func TestToStorageErr(t *testing.T) {
+	tests := []struct {
+		err  error
+		want error
+	}{
+		{minio.ErrorResponse{Code: "NoSuchKey", StatusCode: http.StatusNotFound}, storage.FileNotFoundErr},
+		{minio.ErrorResponse{StatusCode: http.StatusNotFound}, storage.FileNotFoundErr},
+		{minio.ErrorResponse{Code: "AccessDenied", StatusCode: http.StatusForbidden}, storage.FileNoPermissionErr},
+	}
+	for _, tt := range tests {
+		if got := toStorageErr(tt.err); !errors.Is(got, tt.want) {
+			t.Errorf("toStorageErr(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+
+	noSuchBucket := minio.ErrorResponse{Code: "NoSuchBucket", StatusCode: http.StatusNotFound}
+	if got := toStorageErr(noSuchBucket); errors.Is(got, storage.FileNotFoundErr) {
+		t.Error("NoSuchBucket should not map to FileNotFoundErr")
+	}
+	if toStorageErr(nil) != nil {
+		t.Error("toStorageErr(nil) should be nil")
+	}
+}
+
+func TestInitMissingBucket(t *testing.T) {
+	config := testConfig(t)
+	config.Bucket = "missing-" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	if _, err := Init(config); err == nil {
+		t.Fatal("Init() with missing bucket should fail")
+	}
+}
+
+func TestPutGet(t *testing.T) {
+	s, prefix := newTestDisk(t)
+	key := prefix + "a.txt"
+	put(t, s, key, "hello")
+
+	if got := read(t, s, key); got != "hello" {
+		t.Errorf("Get() = %q, want %q", got, "hello")
+	}
+	if exists, err := s.Exists(key); err != nil || !exists {
+		t.Errorf("Exists() = %v, %v, want true", exists, err)
+	}
+	if size, err := s.Size(key); err != nil || size != 5 {
+		t.Errorf("Size() = %d, %v, want 5", size, err)
+	}
+}
+
+func TestMissingKey(t *testing.T) {
+	s, prefix := newTestDisk(t)
+	key := prefix + "missing.txt"
+
+	if _, err := s.Get(key); !errors.Is(err, storage.FileNotFoundErr) {
+		t.Errorf("Get() error = %v, want FileNotFoundErr", err)
+	}
+	if _, err := s.Size(key); !errors.Is(err, storage.FileNotFoundErr) {
+		t.Errorf("Size() error = %v, want FileNotFoundErr", err)
+	}
+	if err := s.Copy(key, prefix+"copy.txt"); !errors.Is(err, storage.FileNotFoundErr) {
+		t.Errorf("Copy() error = %v, want FileNotFoundErr", err)
+	}
+	if exists, err := s.Exists(key); err != nil || exists {
+		t.Errorf("Exists() = %v, %v, want false", exists, err)
+	}
+}
+
+func TestCopyRenameDelete(t *testing.T) {
+	s, prefix := newTestDisk(t)
+	src, copied, renamed := prefix+"src.txt", prefix+"copied.txt", prefix+"renamed.txt"
+	put(t, s, src, "content")
+
+	if err := s.Copy(src, copied); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if got := read(t, s, copied); got != "content" {
+		t.Errorf("copied content = %q", got)
+	}
+
+	if err := s.Rename(copied, renamed); err != nil {
+		t.Fatalf("Rename() error = %v", err)
+	}
+	if exists, _ := s.Exists(copied); exists {
+		t.Error("Rename() should remove the source key")
+	}
+	if got := read(t, s, renamed); got != "content" {
+		t.Errorf("renamed content = %q", got)
+	}
+
+	if err := s.Delete(renamed); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if exists, _ := s.Exists(renamed); exists {
+		t.Error("Delete() should remove the key")
+	}
+}
+
+func TestWalk(t *testing.T) {
+	s, prefix := newTestDisk(t)
+	put(t, s, prefix+"a/1.txt", "1")
+	put(t, s, prefix+"a/b/2.txt", "2")
+	put(t, s, prefix+"c/3.txt", "3")
+
+	var keys []string
+	err := s.Walk(prefix+"a/", func(key string, modTime time.Time) error {
+		if modTime.IsZero() {
+			t.Errorf("modTime of %s is zero", key)
+		}
+		keys = append(keys, key)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() error = %v", err)
+	}
+	sort.Strings(keys)
+	want := []string{prefix + "a/1.txt", prefix + "a/b/2.txt"}
+	if strings.Join(keys, ",") != strings.Join(want, ",") {
+		t.Errorf("Walk() keys = %v, want %v", keys, want)
+	}
+
+	stop := errors.New("stop")
+	if err = s.Walk(prefix, func(key string, modTime time.Time) error { return stop }); !errors.Is(err, stop) {
+		t.Errorf("Walk() should return the callback error, got %v", err)
+	}
+}
+
+func TestTemporaryUrl(t *testing.T) {
+	s, prefix := newTestDisk(t)
+	key := prefix + "private.txt"
+	put(t, s, key, "secret")
+
+	u, err := s.TemporaryUrl(key, time.Minute)
+	if err != nil {
+		t.Fatalf("TemporaryUrl() error = %v", err)
+	}
+	resp, err := http.Get(u)
+	if err != nil {
+		t.Fatalf("GET temporary url error = %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "secret" {
+		t.Errorf("GET temporary url = %d %q, want 200 %q", resp.StatusCode, body, "secret")
+	}
+}
@@ -0,0 +1,89 @@
+package signed
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/jassue/go-storage/storage"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// 签名链接参数
+const (
+	ParamDisk        = "disk"
+	ParamKey         = "key"
+	ParamUserId      = "uid"
+	ParamDisposition = "disposition"
+	ParamFilename    = "filename"
+	ParamExpires     = "expires"
+	ParamSignature   = "signature"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrExpired          = errors.New("url expired")
+)
+
+// Storage 支持生成临时访问链接的文件系统，所有驱动均需实现
+type Storage interface {
+	storage.Storage
+	TemporaryUrl(key string, ttl time.Duration) (string, error)
+}
+
+// Signer 使用 HMAC-SHA256 签发及校验带有效期的下载链接
+type Signer struct {
+	secret  []byte
+	baseUrl string
+}
+
+// NewSigner baseUrl 为下载接口地址，如 http://localhost:8888/api/download
+func NewSigner(secret string, baseUrl string) *Signer {
+	return &Signer{secret: []byte(secret), baseUrl: baseUrl}
+}
+
+// Sign 为参数附加过期时间及签名，返回完整的下载链接
+func (s *Signer) Sign(params url.Values, ttl time.Duration) string {
+	signedParams := url.Values{}
+	for key, values := range params {
+		signedParams[key] = values
+	}
+	signedParams.Set(ParamExpires, strconv.FormatInt(time.Now().Add(ttl).Unix(), 10))
+	signedParams.Set(ParamSignature, s.signature(signedParams))
+	return s.baseUrl + "?" + signedParams.Encode()
+}
+
+// Verify 校验签名及有效期
+func (s *Signer) Verify(params url.Values) error {
+	signature, err := hex.DecodeString(params.Get(ParamSignature))
+	if err != nil || !hmac.Equal(signature, s.sum(params)) {
+		return ErrInvalidSignature
+	}
+	expires, err := strconv.ParseInt(params.Get(ParamExpires), 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if time.Now().Unix() > expires {
+		return ErrExpired
+	}
+	return nil
+}
+
+func (s *Signer) signature(params url.Values) string {
+	return hex.EncodeToString(s.sum(params))
+}
+
+// 对除签名外的参数按键名排序后计算签名
+func (s *Signer) sum(params url.Values) []byte {
+	signedParams := url.Values{}
+	for key, values := range params {
+		if key != ParamSignature {
+			signedParams[key] = values
+		}
+	}
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(signedParams.Encode()))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,69 @@
+package signed
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const testBaseUrl = "http://localhost:8888/api/download"
+
+func signedParams(t *testing.T, signer *Signer, params url.Values, ttl time.Duration) url.Values {
+	t.Helper()
+	link, err := url.Parse(signer.Sign(params, ttl))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if base := link.Scheme + "://" + link.Host + link.Path; base != testBaseUrl {
+		t.Fatalf("got base url %s, want %s", base, testBaseUrl)
+	}
+	return link.Query()
+}
+
+func TestSignerVerify(t *testing.T) {
+	signer := NewSigner("secret", testBaseUrl)
+	params := url.Values{
+		ParamDisk:        {"local"},
+		ParamKey:         {"media/2026/10/a.png"},
+		ParamUserId:      {"1"},
+		ParamDisposition: {"attachment"},
+	}
+
+	tests := []struct {
+		name   string
+		signer *Signer
+		ttl    time.Duration
+		modify func(values url.Values)
+		want   error
+	}{
+		{"valid", signer, time.Minute, func(values url.Values) {}, nil},
+		{"tampered key", signer, time.Minute, func(values url.Values) { values.Set(ParamKey, "media/2026/10/b.png") }, ErrInvalidSignature},
+		{"tampered disposition", signer, time.Minute, func(values url.Values) { values.Set(ParamDisposition, "inline") }, ErrInvalidSignature},
+		{"added filename", signer, time.Minute, func(values url.Values) { values.Set(ParamFilename, "x.png") }, ErrInvalidSignature},
+		{"swapped uid", signer, time.Minute, func(values url.Values) { values.Set(ParamUserId, "2") }, ErrInvalidSignature},
+		{"removed uid", signer, time.Minute, func(values url.Values) { values.Del(ParamUserId) }, ErrInvalidSignature},
+		{"missing signature", signer, time.Minute, func(values url.Values) { values.Del(ParamSignature) }, ErrInvalidSignature},
+		{"malformed signature", signer, time.Minute, func(values url.Values) { values.Set(ParamSignature, "not-hex") }, ErrInvalidSignature},
+		{"other secret", NewSigner("other", testBaseUrl), time.Minute, func(values url.Values) {}, ErrInvalidSignature},
+		{"expired", signer, -time.Minute, func(values url.Values) {}, ErrExpired},
+		{"extended expires", signer, -time.Minute, func(values url.Values) {
+			values.Set(ParamExpires, strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		}, ErrInvalidSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := signedParams(t, tt.signer, params, tt.ttl)
+			tt.modify(values)
+			if err := signer.Verify(values); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// 签发时不修改调用方传入的参数
+	if params.Has(ParamExpires) || params.Has(ParamSignature) {
+		t.Errorf("Sign modified the params: %v", params)
+	}
+}
@@ -0,0 +1,82 @@
+package admin
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"my-gin/app/common/request"
+	"my-gin/app/common/response"
+	"my-gin/global"
+	"time"
+)
+
+// 临时调试用户默认时长
+const defaultDebugMinutes = 10
+
+// LogController 运行时日志等级管理接口
+type LogController struct {
+	levels *global.LogLevels
+}
+
+func NewLogController(levels *global.LogLevels) *LogController {
+	return &LogController{levels: levels}
+}
+
+// GetLogLevel 查看当前日志等级
+func (ctrl *LogController) GetLogLevel(c *gin.Context) {
+	response.Success(c, ctrl.levels.Snapshot())
+}
+
+// SetLogLevel 修改全局或模块日志等级，模块等级为空时恢复跟随全局
+func (ctrl *LogController) SetLogLevel(c *gin.Context) {
+	var form request.LogLevel
+	if err := c.ShouldBindJSON(&form); err != nil {
+		response.ValidateFail(c, request.GetErrorMsg(form, err))
+		return
+	}
+
+	if form.Module == "" {
+		level, err := zapcore.ParseLevel(form.Level)
+		if form.Level == "" || err != nil {
+			response.ValidateFail(c, "日志等级不正确")
+			return
+		}
+		ctrl.levels.Root().SetLevel(level)
+	} else if err := ctrl.levels.SetModuleLevel(form.Module, form.Level); err != nil {
+		response.ValidateFail(c, err.Error())
+		return
+	}
+
+	request.Logger(c).Warn("log level changed", zap.String("module", form.Module), zap.String("level", form.Level))
+	response.Success(c, ctrl.levels.Snapshot())
+}
+
+// DebugUser 临时对指定用户开启 debug 日志
+// 仅作用于 http 层通过 request.Logger 输出的日志，服务层、模块 logger 及 GORM 日志仍按原等级输出
+func (ctrl *LogController) DebugUser(c *gin.Context) {
+	var form request.DebugUser
+	if err := c.ShouldBindJSON(&form); err != nil {
+		response.ValidateFail(c, request.GetErrorMsg(form, err))
+		return
+	}
+
+	if form.Minutes == 0 {
+		form.Minutes = defaultDebugMinutes
+	}
+	ctrl.levels.DebugUser(form.UserId, time.Duration(form.Minutes)*time.Minute)
+
+	request.Logger(c).Warn("debug user enabled", zap.String("target_user_id", form.UserId), zap.Int("minutes", form.Minutes))
+	response.Success(c, ctrl.levels.Snapshot())
+}
+
+// CancelDebugUser 取消用户的临时 debug 日志
+func (ctrl *LogController) CancelDebugUser(c *gin.Context) {
+	var form request.DebugUser
+	if err := c.ShouldBindJSON(&form); err != nil {
+		response.ValidateFail(c, request.GetErrorMsg(form, err))
+		return
+	}
+
+	ctrl.levels.DebugUser(form.UserId, 0)
+	response.Success(c, ctrl.levels.Snapshot())
+}
@@ -0,0 +1,6 @@
+package admin
+
+import "github.com/google/wire"
+
+// ProviderSet admin 控制器 provider
+var ProviderSet = wire.NewSet(NewLogController)
@@ -4,19 +4,18 @@ import (
 	"github.com/gin-gonic/gin"
 	"my-gin/app/common/request"
 	"my-gin/app/common/response"
-	"my-gin/app/service"
 )
 
 // Register 用户注册
-func Register(c *gin.Context) {
+func (ctrl *AuthController) Register(c *gin.Context) {
 	var form request.Register
 	if err := c.ShouldBindJSON(&form); err != nil {
 		response.ValidateFail(c, request.GetErrorMsg(form, err))
 		return
 	}
 
-	if err, user := service.UserService.Register(form); err != nil {
-		response.BusinessFail(c, err.Error())
+	if err, user := ctrl.userService.Register(c.Request.Context(), form); err != nil {
+		c.Error(err)
 	} else {
 		response.Success(c, user)
 	}
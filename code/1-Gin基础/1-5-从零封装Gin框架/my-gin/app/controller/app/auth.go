@@ -6,40 +6,52 @@ import (
 	"my-gin/app/common/request"
 	"my-gin/app/common/response"
 	"my-gin/app/service"
+	"my-gin/global"
 )
 
+// AuthController 用户注册、登录及鉴权相关接口
+type AuthController struct {
+	userService service.UserService
+	jwtService  service.JwtService
+}
+
+func NewAuthController(userService service.UserService, jwtService service.JwtService) *AuthController {
+	return &AuthController{userService: userService, jwtService: jwtService}
+}
+
 // Login 用户登陆
-func Login(c *gin.Context) {
+func (ctrl *AuthController) Login(c *gin.Context) {
 	var form request.Login
 	if err := c.ShouldBindJSON(&form); err != nil {
 		response.ValidateFail(c, request.GetErrorMsg(form, err))
 		return
 	}
 
-	if err, user := service.UserService.Login(form); err != nil {
-		response.BusinessFail(c, err.Error())
+	if err, user := ctrl.userService.Login(c.Request.Context(), form); err != nil {
+		c.Error(err)
 	} else {
-		tokenData, err, _ := service.JwtService.CreateToken(service.AppGuardName, user)
+		tokenData, err, _ := ctrl.jwtService.CreateToken(service.AppGuardName, user)
 		if err != nil {
-			response.BusinessFail(c, err.Error())
+			c.Error(err)
+			return
 		}
 		response.Success(c, tokenData)
 	}
 }
 
-func Info(c *gin.Context) {
-	err, user := service.UserService.GetUserInfo(c.Keys["id"].(string))
+func (ctrl *AuthController) Info(c *gin.Context) {
+	err, user := ctrl.userService.GetUserInfo(c.Request.Context(), c.Keys["id"].(string))
 	if err != nil {
-		response.BusinessFail(c, err.Error())
+		c.Error(err)
 		return
 	}
 	response.Success(c, user)
 }
 
-func LogOut(c *gin.Context) {
-	err := service.JwtService.JoinBlackList(c.Keys["token"].(*jwt.Token))
+func (ctrl *AuthController) LogOut(c *gin.Context) {
+	err := ctrl.jwtService.JoinBlackList(c.Request.Context(), c.Keys["token"].(*jwt.Token))
 	if err != nil {
-		response.BusinessFail(c, "登出失败")
+		c.Error(global.Errors.BusinessError.WithMsg("登出失败").WithCause(err))
 		return
 	}
 	response.Success(c, nil)
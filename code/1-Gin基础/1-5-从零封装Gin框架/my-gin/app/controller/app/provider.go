@@ -0,0 +1,6 @@
+package app
+
+import "github.com/google/wire"
+
+// ProviderSet app 控制器 provider
+var ProviderSet = wire.NewSet(NewAuthController)
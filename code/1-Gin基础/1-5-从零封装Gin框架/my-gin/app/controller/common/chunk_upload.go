@@ -0,0 +1,70 @@
+package common
+
+import (
+	"github.com/gin-gonic/gin"
+	"my-gin/app/common/request"
+	"my-gin/app/common/response"
+	"my-gin/global"
+	"strconv"
+)
+
+// InitUpload 创建分片上传，返回分片大小及数量
+func (ctrl *UploadController) InitUpload(c *gin.Context) {
+	var form request.UploadInit
+	if err := c.ShouldBindJSON(&form); err != nil {
+		response.ValidateFail(c, request.GetErrorMsg(form, err))
+		return
+	}
+
+	session, err := ctrl.mediaService.InitUpload(c.Request.Context(), request.GetUserId(c), form)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	response.Success(c, session)
+}
+
+// UploadStatus 查询已上传的分片，用于断点续传
+func (ctrl *UploadController) UploadStatus(c *gin.Context) {
+	session, err := ctrl.mediaService.UploadStatus(c.Request.Context(), request.GetUserId(c), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	response.Success(c, session)
+}
+
+// UploadChunk 上传分片，请求体为分片内容
+func (ctrl *UploadController) UploadChunk(c *gin.Context) {
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil {
+		c.Error(global.Errors.ChunkIndexError)
+		return
+	}
+
+	err = ctrl.mediaService.UploadChunk(c.Request.Context(), request.GetUserId(c), c.Param("id"), index, c.Request.Body)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	response.Success(c, nil)
+}
+
+// CompleteUpload 合并分片并保存文件
+func (ctrl *UploadController) CompleteUpload(c *gin.Context) {
+	outPut, err := ctrl.mediaService.CompleteUpload(c.Request.Context(), request.GetUserId(c), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	response.Success(c, outPut)
+}
+
+// AbortUpload 取消分片上传
+func (ctrl *UploadController) AbortUpload(c *gin.Context) {
+	if err := ctrl.mediaService.AbortUpload(c.Request.Context(), request.GetUserId(c), c.Param("id")); err != nil {
+		c.Error(err)
+		return
+	}
+	response.Success(c, nil)
+}
@@ -0,0 +1,87 @@
+package common
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"mime"
+	"my-gin/app/common/request"
+	"my-gin/app/common/response"
+	"my-gin/app/common/storage/signed"
+	"my-gin/app/service"
+	"my-gin/global"
+	"net/http"
+	"path"
+	"strconv"
+)
+
+// DownloadController 签名下载链接的签发及文件下载
+type DownloadController struct {
+	signer       *signed.Signer
+	mediaService service.MediaService
+}
+
+func NewDownloadController(signer *signed.Signer, mediaService service.MediaService) *DownloadController {
+	return &DownloadController{signer: signer, mediaService: mediaService}
+}
+
+// SignedUrl 为当前登录用户签发文件下载链接，可指定缩略图
+func (ctrl *DownloadController) SignedUrl(c *gin.Context) {
+	var form request.MediaUrl
+	if err := c.ShouldBindQuery(&form); err != nil {
+		response.ValidateFail(c, request.GetErrorMsg(form, err))
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.ValidateFail(c, "文件 id 不正确")
+		return
+	}
+
+	url, err := ctrl.mediaService.SignedUrlById(c.Request.Context(), id, form.Variant, request.GetUserId(c), form.Disposition)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	response.Success(c, gin.H{"url": url})
+}
+
+// Download 校验签名后输出文件，绑定用户的链接需该用户登录
+func (ctrl *DownloadController) Download(c *gin.Context) {
+	params := c.Request.URL.Query()
+	if err := ctrl.signer.Verify(params); err != nil {
+		if errors.Is(err, signed.ErrExpired) {
+			c.Error(global.Errors.ForbiddenError.WithMsg("下载链接已过期"))
+			return
+		}
+		c.Error(global.Errors.ForbiddenError.WithMsg("下载链接无效"))
+		return
+	}
+	if userId := params.Get(signed.ParamUserId); userId != "" && userId != request.GetUserId(c) {
+		response.TokenFail(c)
+		return
+	}
+
+	key := params.Get(signed.ParamKey)
+	file, size, err := ctrl.mediaService.Open(params.Get(signed.ParamDisk), key)
+	if err != nil {
+		c.Error(global.Errors.MediaNotFoundError.WithCause(err))
+		return
+	}
+	defer file.Close()
+
+	disposition := params.Get(signed.ParamDisposition)
+	if disposition == "" {
+		disposition = "inline"
+	}
+	filename := params.Get(signed.ParamFilename)
+	if filename == "" {
+		filename = path.Base(key)
+	}
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.DataFromReader(http.StatusOK, size, contentType, file, map[string]string{
+		"Content-Disposition": mime.FormatMediaType(disposition, map[string]string{"filename": filename}),
+	})
+}
@@ -0,0 +1,32 @@
+package common
+
+import (
+	"github.com/gin-gonic/gin"
+	"my-gin/app/common/health"
+	"net/http"
+	"time"
+)
+
+// HealthController 存活及就绪检查接口
+type HealthController struct {
+	registry *health.Registry
+}
+
+func NewHealthController(registry *health.Registry) *HealthController {
+	return &HealthController{registry: registry}
+}
+
+// Healthz 存活检查，进程能响应即为存活
+func (ctrl *HealthController) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, health.Report{Status: health.StatusOk, CheckedAt: time.Now()})
+}
+
+// Readyz 就绪检查，任一依赖不可用或正在关闭时返回 503
+func (ctrl *HealthController) Readyz(c *gin.Context) {
+	report := ctrl.registry.Ready(c.Request.Context())
+	status := http.StatusOK
+	if report.Status != health.StatusOk {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
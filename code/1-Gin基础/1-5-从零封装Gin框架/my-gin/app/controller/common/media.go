@@ -0,0 +1,70 @@
+package common
+
+import (
+	"github.com/gin-gonic/gin"
+	"my-gin/app/common/request"
+	"my-gin/app/common/response"
+	"my-gin/app/service"
+	"strconv"
+)
+
+// MediaController 当前用户上传文件的查询及删除
+type MediaController struct {
+	mediaService service.MediaService
+}
+
+func NewMediaController(mediaService service.MediaService) *MediaController {
+	return &MediaController{mediaService: mediaService}
+}
+
+// List 分页查询我的文件，可按业务类型筛选
+func (ctrl *MediaController) List(c *gin.Context) {
+	var form request.MediaList
+	if err := c.ShouldBindQuery(&form); err != nil {
+		response.ValidateFail(c, request.GetErrorMsg(form, err))
+		return
+	}
+
+	list, total, err := ctrl.mediaService.ListMedia(c.Request.Context(), request.GetUserId(c), form)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	response.Success(c, response.PageData{
+		List:     list,
+		Total:    total,
+		Page:     form.GetPage(),
+		PageSize: form.GetPageSize(),
+	})
+}
+
+// Show 文件详情
+func (ctrl *MediaController) Show(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.ValidateFail(c, "文件 id 不正确")
+		return
+	}
+
+	info, err := ctrl.mediaService.GetMedia(c.Request.Context(), request.GetUserId(c), id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	response.Success(c, info)
+}
+
+// Delete 删除文件
+func (ctrl *MediaController) Delete(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.ValidateFail(c, "文件 id 不正确")
+		return
+	}
+
+	if err = ctrl.mediaService.DeleteMedia(c.Request.Context(), request.GetUserId(c), id); err != nil {
+		c.Error(err)
+		return
+	}
+	response.Success(c, nil)
+}
@@ -0,0 +1,6 @@
+package common
+
+import "github.com/google/wire"
+
+// ProviderSet common 控制器 provider
+var ProviderSet = wire.NewSet(NewUploadController, NewDownloadController, NewMediaController, NewHealthController)
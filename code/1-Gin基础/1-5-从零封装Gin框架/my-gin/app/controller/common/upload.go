@@ -7,16 +7,42 @@ import (
 	"my-gin/app/service"
 )
 
-func ImageUpload(c *gin.Context) {
+// UploadController 文件上传接口
+type UploadController struct {
+	mediaService service.MediaService
+}
+
+func NewUploadController(mediaService service.MediaService) *UploadController {
+	return &UploadController{mediaService: mediaService}
+}
+
+// ImageUpload 图片上传
+func (ctrl *UploadController) ImageUpload(c *gin.Context) {
 	var form request.ImageUpload
 	if err := c.ShouldBind(&form); err != nil {
 		response.ValidateFail(c, request.GetErrorMsg(form, err))
 		return
 	}
 
-	outPut, err := service.MediaService.SaveImage(form)
+	outPut, err := ctrl.mediaService.SaveImage(c.Request.Context(), request.GetUserId(c), form)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	response.Success(c, outPut)
+}
+
+// FileUpload 文件上传，文件类型、大小由业务类型的上传策略决定
+func (ctrl *UploadController) FileUpload(c *gin.Context) {
+	var form request.FileUpload
+	if err := c.ShouldBind(&form); err != nil {
+		response.ValidateFail(c, request.GetErrorMsg(form, err))
+		return
+	}
+
+	outPut, err := ctrl.mediaService.SaveFile(c.Request.Context(), request.GetUserId(c), form)
 	if err != nil {
-		response.BusinessFail(c, err.Error())
+		c.Error(err)
 		return
 	}
 	response.Success(c, outPut)
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"math/rand"
+	"my-gin/app/common/request"
+	"my-gin/config"
+	"my-gin/utils"
+	"net/http"
+	"time"
+)
+
+// AccessLog 结构化访问日志，替代 gin.Logger()，通过 zap 统一写入
+type AccessLog struct {
+	conf     *config.Store
+	redactor *utils.Redactor
+}
+
+func NewAccessLog(conf *config.Store, redactor *utils.Redactor) *AccessLog {
+	return &AccessLog{conf: conf, redactor: redactor}
+}
+
+func (m *AccessLog) Handle() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		conf := m.conf.Load().Log.AccessLog
+		path := c.Request.URL.Path
+		for _, skip := range conf.SkipPaths {
+			if skip == path {
+				c.Next()
+				return
+			}
+		}
+
+		start := time.Now()
+		c.Next()
+		latency := time.Since(start)
+
+		status := c.Writer.Status()
+		// 错误请求全部记录，正常请求按采样率记录
+		if status < http.StatusBadRequest && conf.SampleRate > 0 && conf.SampleRate < 1 && rand.Float64() >= conf.SampleRate {
+			return
+		}
+
+		size := c.Writer.Size()
+		if size < 0 {
+			size = 0
+		}
+
+		fields := []zap.Field{
+			zap.String("method", c.Request.Method),
+			zap.String("route", c.FullPath()),
+			zap.String("path", path),
+			zap.String("query", m.redactor.Values(c.Request.URL.Query()).Encode()),
+			zap.Int("status", status),
+			zap.Duration("latency", latency),
+			zap.Int("bytes", size),
+			zap.String("user_id", request.GetUserId(c)),
+			zap.String("client_ip", c.ClientIP()),
+		}
+		if len(c.Errors) > 0 {
+			fields = append(fields, zap.String("errors", c.Errors.ByType(gin.ErrorTypePrivate).String()))
+		}
+
+		logger := request.Logger(c)
+		switch {
+		case status >= http.StatusInternalServerError:
+			logger.Error("access", fields...)
+		case status >= http.StatusBadRequest:
+			logger.Warn("access", fields...)
+		default:
+			logger.Info("access", fields...)
+		}
+	}
+}
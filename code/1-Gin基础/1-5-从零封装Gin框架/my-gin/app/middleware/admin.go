@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"github.com/gin-gonic/gin"
+	"my-gin/app/common/response"
+	"my-gin/config"
+)
+
+const AdminTokenHeader = "X-Admin-Token"
+
+// AdminAuth 管理接口鉴权，未配置 admin.token 时拒绝所有请求
+func AdminAuth(conf *config.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := conf.Load().Admin.Token
+		if token == "" || subtle.ConstantTimeCompare([]byte(c.GetHeader(AdminTokenHeader)), []byte(token)) != 1 {
+			response.TokenFail(c)
+			c.Abort()
+			return
+		}
+	}
+}
@@ -3,14 +3,42 @@ package middleware
 import (
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"my-gin/config"
+	"sync/atomic"
 )
 
-func Cors() gin.HandlerFunc {
+// Cors 跨域处理，cors.allow_origins 变更时重建处理函数
+type Cors struct {
+	handler atomic.Pointer[gin.HandlerFunc]
+}
+
+func NewCors(conf *config.Store) *Cors {
+	m := &Cors{}
+	m.handler.Store(newCorsHandler(conf.Load().Cors))
+	conf.Subscribe(func(_, conf *config.Configuration) {
+		m.handler.Store(newCorsHandler(conf.Cors))
+	}, "cors")
+	return m
+}
+
+func (m *Cors) Handle() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		(*m.handler.Load())(c)
+	}
+}
+
+func newCorsHandler(conf config.Cors) *gin.HandlerFunc {
 	config := cors.DefaultConfig()
-	config.AllowAllOrigins = true
-	config.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization"}
+	// 未配置来源时允许所有来源
+	if len(conf.AllowOrigins) == 0 {
+		config.AllowAllOrigins = true
+	} else {
+		config.AllowOrigins = conf.AllowOrigins
+	}
+	config.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization", "X-Request-ID", "traceparent", "tracestate"}
 	config.AllowCredentials = true
-	config.ExposeHeaders = []string{"New-Token", "New-Expires-In", "Content-Disposition"}
+	config.ExposeHeaders = []string{"New-Token", "New-Expires-In", "Content-Disposition", "X-Request-ID"}
 
-	return cors.New(config)
+	handler := cors.New(config)
+	return &handler
 }
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"my-gin/app/common/request"
+	"my-gin/app/common/response"
+	"my-gin/config"
+	"net/http"
+)
+
+// ErrorHandler 统一输出控制器通过 c.Error(err) 记录的错误，只处理最后一个错误
+// 服务器内部错误记录原始错误，客户端只能看到错误码及通用错误信息
+type ErrorHandler struct {
+	conf *config.Store
+}
+
+func NewErrorHandler(conf *config.Store) *ErrorHandler {
+	return &ErrorHandler{conf: conf}
+}
+
+func (m *ErrorHandler) Handle() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// 记录本次请求使用的错误响应格式，供 response 包读取
+		c.Set(response.FormatKey, m.conf.Load().App.ErrorFormat)
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		customError := response.AsCustomError(c.Errors.Last().Err)
+		if customError.HttpStatus >= http.StatusInternalServerError {
+			request.Logger(c).Error("request failed",
+				zap.Int("error_code", customError.ErrorCode),
+				zap.String("route", c.FullPath()),
+				zap.Error(customError.Cause),
+			)
+		}
+		response.Fail(c, customError)
+	}
+}
@@ -3,14 +3,31 @@ package middleware
 import (
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"my-gin/app/common/request"
 	"my-gin/app/common/response"
 	"my-gin/app/service"
+	"my-gin/config"
 	"my-gin/global"
 	"strconv"
 	"time"
 )
 
-func JWTAuth(GuardName string) gin.HandlerFunc {
+// JwtAuth jwt 鉴权中间件
+type JwtAuth struct {
+	conf       *config.Store
+	jwtService service.JwtService
+	locker     *global.Locker
+	levels     *global.LogLevels
+	log        *zap.Logger
+}
+
+func NewJwtAuth(conf *config.Store, jwtService service.JwtService, locker *global.Locker, levels *global.LogLevels, moduleLog global.ModuleLogger) *JwtAuth {
+	return &JwtAuth{conf: conf, jwtService: jwtService, locker: locker, levels: levels, log: moduleLog(global.LogModuleJwt)}
+}
+
+// Handle 校验指定客户端的 token，临近过期时续签
+func (m *JwtAuth) Handle(GuardName string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenStr := c.Request.Header.Get("Authorization")
 		if tokenStr == "" {
@@ -21,11 +38,11 @@ func JWTAuth(GuardName string) gin.HandlerFunc {
 
 		// Token 解析校验
 		token, err := jwt.ParseWithClaims(tokenStr, &service.CustomClaims{}, func(token *jwt.Token) (interface{}, error) {
-			return []byte(global.App.Config.Jwt.Secret), nil
+			return []byte(m.conf.Load().Jwt.Secret), nil
 		})
 
-		if err != nil || service.JwtService.IsInBlacklist(tokenStr) {
-			global.App.Log.Error("can't pass the jwt token validator ")
+		if err != nil || m.jwtService.IsInBlacklist(c.Request.Context(), tokenStr) {
+			m.log.Error("can't pass the jwt token validator ")
 			response.TokenFail(c)
 			c.Abort()
 			return
@@ -41,24 +58,39 @@ func JWTAuth(GuardName string) gin.HandlerFunc {
 		}
 
 		// token 续签处理
-		if claims.ExpiresAt-time.Now().Unix() < global.App.Config.Jwt.RefreshGracePeriod {
-			lock := global.Lock("refresh_token_local_"+claims.Id, global.App.Config.Jwt.JwtBlacklistGracePeriod)
+		if claims.ExpiresAt-time.Now().Unix() < m.conf.Load().Jwt.RefreshGracePeriod {
+			lock := m.locker.Lock("refresh_token_local_"+claims.Id, m.conf.Load().Jwt.JwtBlacklistGracePeriod)
 			if lock.Get() {
-				err, user := service.JwtService.GetUserInfo(GuardName, claims.Id)
+				err, user := m.jwtService.GetUserInfo(c.Request.Context(), GuardName, claims.Id)
 				if err != nil {
-					global.App.Log.Error("service.JwtService.GetUserInfo error!")
+					m.log.Error("m.jwtService.GetUserInfo error!")
 					lock.Release()
 				} else {
-					tokenData, _, _ := service.JwtService.CreateToken(GuardName, user)
+					tokenData, _, _ := m.jwtService.CreateToken(GuardName, user)
 					c.Header("new-token", tokenData.AccessToken)
 					c.Header("new-expires-in", strconv.Itoa(tokenData.ExpiresIn))
-					_ = service.JwtService.JoinBlackList(token)
+					_ = m.jwtService.JoinBlackList(c.Request.Context(), token)
 					lock.Release()
 				}
 			}
 		}
 
 		c.Set("token", token)
-		c.Set("id", claims.Id)
+		request.SetUserId(c, claims.Id)
+		// 用户处于临时调试状态时，该请求的请求级 logger 等级降为 debug，不影响模块 logger 及 GORM 日志
+		if m.levels.IsDebugUser(claims.Id) {
+			request.SetLogger(c, global.WithLevel(request.Logger(c), zap.DebugLevel))
+		}
+	}
+}
+
+// Optional 携带 token 时按 Handle 校验，未携带时直接放行，由接口自行判断是否需要登录
+func (m *JwtAuth) Optional(GuardName string) gin.HandlerFunc {
+	handle := m.Handle(GuardName)
+	return func(c *gin.Context) {
+		if c.Request.Header.Get("Authorization") == "" {
+			return
+		}
+		handle(c)
 	}
 }
@@ -0,0 +1,23 @@
+package middleware
+
+import "github.com/google/wire"
+
+// Global 全局中间件
+type Global struct {
+	RequestId    *RequestId
+	AccessLog    *AccessLog
+	Recovery     *Recovery
+	ErrorHandler *ErrorHandler
+	Cors         *Cors
+}
+
+// ProviderSet 需要注入依赖的中间件
+var ProviderSet = wire.NewSet(
+	NewRequestId,
+	NewAccessLog,
+	NewRecovery,
+	NewErrorHandler,
+	NewCors,
+	NewJwtAuth,
+	wire.Struct(new(Global), "*"),
+)
@@ -1,22 +1,114 @@
 package middleware
 
 import (
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
-	"gopkg.in/natefinch/lumberjack.v2"
+	"go.uber.org/zap"
+	"my-gin/app/common/alert"
+	"my-gin/app/common/request"
 	"my-gin/app/common/response"
-	"my-gin/global"
+	"my-gin/config"
+	"my-gin/utils"
+	"net"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"strings"
+	"syscall"
 )
 
-// CustomRecovery 自定义错误回复，带有写入日志功能
-func CustomRecovery() gin.HandlerFunc {
-	return gin.RecoveryWithWriter(
-		&lumberjack.Logger{
-			Filename:   global.App.Config.Log.RootDir + "/" + global.App.Config.Log.Filename,
-			MaxSize:    global.App.Config.Log.MaxSize,
-			MaxBackups: global.App.Config.Log.MaxBackups,
-			MaxAge:     global.App.Config.Log.MaxAge,
-			Compress:   global.App.Config.Log.Compress,
-		},
-		response.ServerError,
-	)
+// Recovery 捕获 panic，记录堆栈及请求上下文，返回统一错误响应并发送告警
+type Recovery struct {
+	conf     *config.Store
+	redactor *utils.Redactor
+	alert    *alert.Dispatcher
+}
+
+func NewRecovery(conf *config.Store, redactor *utils.Redactor, dispatcher *alert.Dispatcher) *Recovery {
+	return &Recovery{conf: conf, redactor: redactor, alert: dispatcher}
+}
+
+func (m *Recovery) Handle() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			// http.ErrAbortHandler 用于主动中止响应，交由 net/http 处理
+			if err, ok := r.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(r)
+			}
+
+			stack := string(debug.Stack())
+			message := m.redactor.String(fmt.Sprint(r))
+			route := c.FullPath()
+			logger := request.Logger(c)
+
+			// 客户端断开连接时无法再写入响应，只记录日志
+			if isBrokenPipe(r) {
+				logger.Warn("connection broken", zap.String("panic", message), zap.String("route", route))
+				_ = c.Error(fmt.Errorf("%v", r))
+				c.Abort()
+				return
+			}
+
+			logger.Error("panic recovered",
+				zap.String("panic", message),
+				zap.String("route", route),
+				zap.String("method", c.Request.Method),
+				zap.String("path", c.Request.URL.Path),
+				zap.String("query", m.redactor.Values(c.Request.URL.Query()).Encode()),
+				zap.Any("header", m.redactor.Header(c.Request.Header)),
+				zap.String("client_ip", c.ClientIP()),
+				zap.String("stack", stack),
+			)
+
+			conf := m.conf.Load().App
+			m.alert.Fire(route+"|"+message, alert.Alert{
+				Level:   "panic",
+				Title:   conf.AppName + " panic",
+				Message: message,
+				Fields: map[string]string{
+					"env":        conf.Env,
+					"request_id": request.GetRequestId(c),
+					"route":      route,
+					"method":     c.Request.Method,
+					"user_id":    request.GetUserId(c),
+				},
+			})
+
+			// 响应已开始写出时无法再返回错误响应
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			// 非生产环境返回具体错误，便于调试
+			var detail string
+			if err, ok := r.(error); ok && conf.Env != "production" && os.Getenv(gin.EnvGinMode) != gin.ReleaseMode {
+				detail = m.redactor.String(err.Error())
+			}
+			response.ServerError(c, detail)
+		}()
+		c.Next()
+	}
+}
+
+// 判断 panic 是否由客户端断开连接引起
+func isBrokenPipe(r interface{}) bool {
+	err, ok := r.(error)
+	if !ok {
+		return false
+	}
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) {
+		return false
+	}
+	var sysErr *os.SyscallError
+	if errors.As(opErr, &sysErr) {
+		return errors.Is(sysErr.Err, syscall.EPIPE) || errors.Is(sysErr.Err, syscall.ECONNRESET)
+	}
+	msg := strings.ToLower(opErr.Error())
+	return strings.Contains(msg, "broken pipe") || strings.Contains(msg, "connection reset by peer")
 }
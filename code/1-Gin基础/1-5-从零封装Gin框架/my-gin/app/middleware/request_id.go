@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	uuid "github.com/satori/go.uuid"
+	"go.uber.org/zap"
+	"my-gin/app/common/request"
+	"my-gin/global"
+)
+
+// 上游传入的请求 ID 最大长度，超出则重新生成，防止日志被恶意填充
+const maxRequestIdLength = 64
+
+// RequestId 分配或透传 X-Request-ID，并在上下文中存放带有请求 ID 的 logger
+type RequestId struct {
+	log *zap.Logger
+}
+
+func NewRequestId(moduleLog global.ModuleLogger) *RequestId {
+	return &RequestId{log: moduleLog(global.LogModuleHttp)}
+}
+
+func (m *RequestId) Handle() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestId := c.GetHeader(request.RequestIdHeader)
+		if requestId == "" || len(requestId) > maxRequestIdLength {
+			requestId = uuid.NewV4().String()
+		}
+
+		c.Set(request.RequestIdKey, requestId)
+		request.SetLogger(c, m.log.With(zap.String(request.RequestIdKey, requestId)))
+		c.Header(request.RequestIdHeader, requestId)
+
+		c.Next()
+	}
+}
@@ -1,9 +1,53 @@
 package models
 
+import "path"
+
+// 文件可见性
+const (
+	MediaPublic  = "public"  // 公开，可直接通过 url 访问
+	MediaPrivate = "private" // 私有，仅能通过签名链接下载
+)
+
+// Media 上传的文件，内容相同（SHA-256 相同）的文件共用同一存储对象，
+// 存储对象的引用计数即引用该对象的 Media 记录数
 type Media struct {
 	ID
-	DiskType string `json:"disk_type" gorm:"size:20;index;not null;comment:存储类型"`
-	ScrType  int8   `json:"src_type" gorm:"not null;comment:连接类型 1-相对路径 2-外链"`
+	UserId     uint           `json:"user_id" gorm:"not null;default:0;index;comment:上传用户 id"`
+	Business   string         `json:"business" gorm:"size:50;not null;default:'';comment:业务类型"`
+	DiskType   string         `json:"disk_type" gorm:"size:20;index;not null;comment:存储类型"`
+	ScrType    int8           `json:"src_type" gorm:"not null;comment:连接类型 1-相对路径 2-外链"`
+	Src        string         `json:"src" gorm:"not null;comment:资源链接"`
+	Visibility string         `json:"visibility" gorm:"size:10;not null;default:public;comment:可见性 public-公开 private-私有"`
+	Name       string         `json:"name" gorm:"size:255;not null;default:'';comment:原始文件名"`
+	MimeType   string         `json:"mime_type" gorm:"size:100;not null;default:'';comment:按文件内容识别的 MIME 类型"`
+	Size       int64          `json:"size" gorm:"not null;default:0;comment:文件大小 字节"`
+	Sha256     string         `json:"sha256" gorm:"size:64;not null;default:'';index;comment:存储文件的 SHA-256"`
+	Variants   []MediaVariant `json:"variants,omitempty" gorm:"foreignKey:MediaId"`
+	Timestamp
+}
+
+// MediaVariant 图片缩略图，与原图使用相同的存储驱动及可见性
+type MediaVariant struct {
+	ID
+	MediaId  uint   `json:"media_id" gorm:"not null;uniqueIndex:idx_media_variant;comment:原图 id"`
+	Name     string `json:"name" gorm:"size:50;not null;uniqueIndex:idx_media_variant;comment:缩略图名称"`
 	Src      string `json:"src" gorm:"not null;comment:资源链接"`
+	MimeType string `json:"mime_type" gorm:"size:100;not null;comment:MIME 类型"`
+	Width    int    `json:"width" gorm:"not null;comment:宽度 像素"`
+	Height   int    `json:"height" gorm:"not null;comment:高度 像素"`
+	Size     int64  `json:"size" gorm:"not null;comment:文件大小 字节"`
 	Timestamp
 }
+
+// IsPrivate 是否为私有文件
+func (media Media) IsPrivate() bool {
+	return media.Visibility == MediaPrivate
+}
+
+// DownloadName 下载时使用的文件名，优先使用原始文件名
+func (media Media) DownloadName() string {
+	if media.Name != "" {
+		return media.Name
+	}
+	return path.Base(media.Src)
+}
@@ -0,0 +1,498 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"github.com/jassue/go-storage/storage"
+	uuid "github.com/satori/go.uuid"
+	"go.uber.org/zap"
+	"io"
+	"my-gin/app/common/request"
+	"my-gin/app/models"
+	"my-gin/config"
+	"my-gin/global"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	uploadKeyPre      = "upload:"
+	uploadExpiringKey = "upload:expiring" // 按过期时间排序的分片上传，成员为 <disk>:<uploadId>
+	defaultChunkSize  = 5 << 20
+	defaultChunkTtl   = config.DefaultChunkTtl * time.Second
+	// Redis 中的上传记录比过期时间多保留一段时间，便于清理任务找到已上传的分片
+	uploadKeepAlive = config.UploadKeepAlive * time.Second
+	// 合并分片的锁超时时间 秒
+	uploadCompleteLockSeconds = 600
+)
+
+// UploadSession 分片上传状态，uploaded 为已上传的分片序号，用于断点续传
+type UploadSession struct {
+	UploadId    string `json:"upload_id"`
+	ChunkSize   int64  `json:"chunk_size"`
+	TotalChunks int    `json:"total_chunks"`
+	Uploaded    []int  `json:"uploaded"`
+	ExpiresAt   int64  `json:"expires_at"`
+}
+
+// 保存在 Redis 中的分片上传信息
+type chunkUpload struct {
+	UserId     string `redis:"user_id"`
+	Business   string `redis:"business"`
+	Filename   string `redis:"filename"`
+	Size       int64  `redis:"size"`
+	Checksum   string `redis:"checksum"`
+	Visibility string `redis:"visibility"`
+	Disk       string `redis:"disk"`
+	ChunkSize  int64  `redis:"chunk_size"`
+	Total      int    `redis:"total"`
+}
+
+func (upload chunkUpload) values() map[string]interface{} {
+	return map[string]interface{}{
+		"user_id":    upload.UserId,
+		"business":   upload.Business,
+		"filename":   upload.Filename,
+		"size":       upload.Size,
+		"checksum":   upload.Checksum,
+		"visibility": upload.Visibility,
+		"disk":       upload.Disk,
+		"chunk_size": upload.ChunkSize,
+		"total":      upload.Total,
+	}
+}
+
+// 分片的大小，最后一个分片可能小于分片大小
+func (upload chunkUpload) chunkLength(index int) int64 {
+	if index == upload.Total-1 {
+		return upload.Size - upload.ChunkSize*int64(upload.Total-1)
+	}
+	return upload.ChunkSize
+}
+
+func (upload chunkUpload) chunkKeys(uploadId string) []string {
+	keys := make([]string, upload.Total)
+	for i := range keys {
+		keys[i] = chunkKey(upload.Disk, uploadId, i)
+	}
+	return keys
+}
+
+// 分片以私有文件形式存放在 chunks/<uploadId> 目录
+func chunkKey(diskType string, uploadId string, index int) string {
+	return chunkPrefix(diskType, uploadId) + strconv.Itoa(index)
+}
+
+func chunkPrefix(diskType string, uploadId string) string {
+	return storageKey(diskType, models.MediaPrivate, "chunks/"+uploadId+"/")
+}
+
+// 过期队列的成员包含存储驱动，上传记录过期后仍能找到分片所在的驱动
+func expiringMember(diskType string, uploadId string) string {
+	return diskType + ":" + uploadId
+}
+
+func uploadKey(uploadId string) string {
+	return uploadKeyPre + uploadId
+}
+
+func uploadChunksKey(uploadId string) string {
+	return uploadKeyPre + uploadId + ":chunks"
+}
+
+// InitUpload 创建分片上传，文件大小按业务类型的上传策略校验
+func (mediaService *mediaService) InitUpload(ctx context.Context, userId string, params request.UploadInit) (session UploadSession, err error) {
+	business := strings.ToLower(params.Business)
+	policy, err := uploadPolicy(mediaService.conf.Load().Upload, business)
+	if err != nil {
+		return
+	}
+	if err = policy.checkSize(params.Size); err != nil {
+		return
+	}
+
+	chunkSize := mediaService.conf.Load().Upload.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+	diskType := policy.Disk
+	if diskType == "" {
+		diskType = string(mediaService.conf.Load().Storage.Default)
+	}
+	upload := chunkUpload{
+		UserId:     userId,
+		Business:   business,
+		Filename:   path.Base(params.Filename),
+		Size:       params.Size,
+		Checksum:   strings.ToLower(params.Checksum),
+		Visibility: params.Visibility,
+		Disk:       diskType,
+		ChunkSize:  chunkSize,
+		Total:      int((params.Size + chunkSize - 1) / chunkSize),
+	}
+	uploadId := strings.ReplaceAll(uuid.NewV4().String(), "-", "")
+
+	if err = mediaService.redis.HSet(ctx, uploadKey(uploadId), upload.values()).Err(); err != nil {
+		return
+	}
+	expiresAt, err := mediaService.touchUpload(ctx, upload.Disk, uploadId)
+	if err != nil {
+		return
+	}
+	return UploadSession{uploadId, chunkSize, upload.Total, []int{}, expiresAt}, nil
+}
+
+// UploadStatus 查询已上传的分片
+func (mediaService *mediaService) UploadStatus(ctx context.Context, userId string, uploadId string) (session UploadSession, err error) {
+	upload, err := mediaService.loadUpload(ctx, userId, uploadId)
+	if err != nil {
+		return
+	}
+	members, err := mediaService.redis.SMembers(ctx, uploadChunksKey(uploadId)).Result()
+	if err != nil {
+		return
+	}
+	uploaded := make([]int, 0, len(members))
+	for _, member := range members {
+		if index, err := strconv.Atoi(member); err == nil {
+			uploaded = append(uploaded, index)
+		}
+	}
+	sort.Ints(uploaded)
+	expiresAt, err := mediaService.redis.ZScore(ctx, uploadExpiringKey, expiringMember(upload.Disk, uploadId)).Result()
+	if err != nil && err != redis.Nil {
+		return
+	}
+	return UploadSession{uploadId, upload.ChunkSize, upload.Total, uploaded, int64(expiresAt)}, nil
+}
+
+// UploadChunk 上传分片，重复上传同一分片时覆盖
+func (mediaService *mediaService) UploadChunk(ctx context.Context, userId string, uploadId string, index int, r io.Reader) error {
+	upload, err := mediaService.loadUpload(ctx, userId, uploadId)
+	if err != nil {
+		return err
+	}
+	if index < 0 || index >= upload.Total {
+		return global.Errors.ChunkIndexError.WithDetail("total", upload.Total)
+	}
+
+	// 多读取一个字节以判断分片是否超出大小
+	length := upload.chunkLength(index)
+	chunk, err := io.ReadAll(io.LimitReader(r, length+1))
+	if err != nil {
+		return err
+	}
+	if int64(len(chunk)) != length {
+		return global.Errors.ChunkSizeError.WithMsg("分片大小应为 "+strconv.FormatInt(length, 10)+" 字节").WithDetail("size", length)
+	}
+
+	disk, err := mediaService.disks(upload.Disk)
+	if err != nil {
+		return err
+	}
+	if err = disk.Put(chunkKey(upload.Disk, uploadId, index), bytes.NewReader(chunk), length); err != nil {
+		return err
+	}
+	if err = mediaService.redis.SAdd(ctx, uploadChunksKey(uploadId), index).Err(); err != nil {
+		return err
+	}
+	_, err = mediaService.touchUpload(ctx, upload.Disk, uploadId)
+	return err
+}
+
+// CompleteUpload 合并分片，校验文件类型及 sha256 后保存为媒体文件
+func (mediaService *mediaService) CompleteUpload(ctx context.Context, userId string, uploadId string) (result outPut, err error) {
+	lock := mediaService.locker.Lock("upload_complete:"+uploadId, uploadCompleteLockSeconds)
+	if !lock.Get() {
+		err = global.Errors.UploadCompletingError
+		return
+	}
+	defer lock.Release()
+
+	upload, err := mediaService.loadUpload(ctx, userId, uploadId)
+	if err != nil {
+		return
+	}
+	uploaded, err := mediaService.redis.SCard(ctx, uploadChunksKey(uploadId)).Result()
+	if err != nil {
+		return
+	}
+	if int(uploaded) != upload.Total {
+		err = global.Errors.UploadIncompleteError.WithDetail("uploaded", uploaded).WithDetail("total", upload.Total)
+		return
+	}
+
+	policy, err := uploadPolicy(mediaService.conf.Load().Upload, upload.Business)
+	if err != nil {
+		return
+	}
+	disk, err := mediaService.disks(upload.Disk)
+	if err != nil {
+		return
+	}
+	keys := upload.chunkKeys(uploadId)
+
+	// 按第一个分片识别文件类型
+	first, err := readChunk(disk, keys[0])
+	if err != nil {
+		return
+	}
+	mimeType, err := policy.check(bytes.NewReader(first), false)
+	if err != nil {
+		_ = removeChunkUpload(ctx, mediaService.redis, mediaService.disks, upload.Disk, uploadId)
+		return
+	}
+
+	target := mediaService.uploadTarget(policy, upload.Business, upload.Visibility, mimeType.Extension())
+	chunks := &chunkReader{disk: disk, keys: keys}
+	defer chunks.Close()
+	checksumFail := func() error {
+		_ = removeChunkUpload(ctx, mediaService.redis, mediaService.disks, upload.Disk, uploadId)
+		return global.Errors.UploadChecksumError
+	}
+
+	var stored storedFile
+	if policy.processImage(mimeType) {
+		// 图片需处理后保存，校验通过后再写入
+		data, e := io.ReadAll(chunks)
+		if e != nil {
+			err = e
+			return
+		}
+		if sum := sha256.Sum256(data); hex.EncodeToString(sum[:]) != upload.Checksum {
+			err = checksumFail()
+			return
+		}
+		if stored, err = mediaService.putImage(ctx, policy, target, data); err != nil {
+			return
+		}
+	} else {
+		if stored, err = mediaService.putFile(ctx, target, chunks, upload.Size); err != nil {
+			return
+		}
+		if stored.sha256 != upload.Checksum {
+			mediaService.removeStored(stored)
+			err = checksumFail()
+			return
+		}
+	}
+
+	if result, err = mediaService.createMedia(ctx, upload.UserId, upload.Business, upload.Filename, mimeType.String(), stored); err != nil {
+		return
+	}
+	err = removeChunkUpload(ctx, mediaService.redis, mediaService.disks, upload.Disk, uploadId)
+	return
+}
+
+// AbortUpload 取消分片上传并删除已上传的分片
+func (mediaService *mediaService) AbortUpload(ctx context.Context, userId string, uploadId string) error {
+	upload, err := mediaService.loadUpload(ctx, userId, uploadId)
+	if err != nil {
+		return err
+	}
+	return removeChunkUpload(ctx, mediaService.redis, mediaService.disks, upload.Disk, uploadId)
+}
+
+// 读取上传信息，仅创建者可操作
+func (mediaService *mediaService) loadUpload(ctx context.Context, userId string, uploadId string) (upload chunkUpload, err error) {
+	cmd := mediaService.redis.HGetAll(ctx, uploadKey(uploadId))
+	if err = cmd.Err(); err != nil {
+		return
+	}
+	if len(cmd.Val()) == 0 {
+		err = global.Errors.UploadNotFoundError
+		return
+	}
+	if err = cmd.Scan(&upload); err != nil {
+		return
+	}
+	if upload.UserId != userId {
+		err = global.Errors.UploadNotFoundError
+	}
+	return
+}
+
+// 延长上传的过期时间，返回新的过期时间
+func (mediaService *mediaService) touchUpload(ctx context.Context, diskType string, uploadId string) (int64, error) {
+	ttl := defaultChunkTtl
+	if chunkTtl := mediaService.conf.Load().Upload.ChunkTtl; chunkTtl > 0 {
+		ttl = time.Duration(chunkTtl) * time.Second
+	}
+	expiresAt := time.Now().Add(ttl).Unix()
+
+	_, err := mediaService.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Expire(ctx, uploadKey(uploadId), ttl+uploadKeepAlive)
+		pipe.Expire(ctx, uploadChunksKey(uploadId), ttl+uploadKeepAlive)
+		pipe.ZAdd(ctx, uploadExpiringKey, &redis.Z{Score: float64(expiresAt), Member: expiringMember(diskType, uploadId)})
+		return nil
+	})
+	return expiresAt, err
+}
+
+// 删除已上传的分片及上传记录，遍历分片目录删除，不依赖可能已过期的上传记录
+// 存储驱动不可用时返回错误，保留上传记录以便重试
+func removeChunkUpload(ctx context.Context, rdb *redis.Client, disks Disks, diskType string, uploadId string) error {
+	disk, err := disks(diskType)
+	if err != nil {
+		return err
+	}
+	walker, ok := disk.(walkableStorage)
+	if !ok {
+		return fmt.Errorf("disk %s does not support walking chunks", diskType)
+	}
+	var keys []string
+	if err = walker.Walk(chunkPrefix(diskType, uploadId), func(key string, modTime time.Time) error {
+		keys = append(keys, key)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, key := range keys {
+		errs = append(errs, disk.Delete(key))
+	}
+	errs = append(errs, rdb.Del(ctx, uploadKey(uploadId), uploadChunksKey(uploadId)).Err())
+	errs = append(errs, rdb.ZRem(ctx, uploadExpiringKey, expiringMember(diskType, uploadId)).Err())
+	return errors.Join(errs...)
+}
+
+func readChunk(disk storage.Storage, key string) ([]byte, error) {
+	file, err := disk.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+// chunkReader 按顺序读取全部分片
+type chunkReader struct {
+	disk    storage.Storage
+	keys    []string
+	current io.ReadCloser
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.keys) == 0 {
+				return 0, io.EOF
+			}
+			file, err := r.disk.Get(r.keys[0])
+			if err != nil {
+				return 0, err
+			}
+			r.current, r.keys = file, r.keys[1:]
+		}
+		n, err := r.current.Read(p)
+		if err == io.EOF {
+			_ = r.current.Close()
+			r.current = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (r *chunkReader) Close() error {
+	if r.current == nil {
+		return nil
+	}
+	return r.current.Close()
+}
+
+// UploadCleaner 定期清理过期未完成的分片上传
+type UploadCleaner struct {
+	redis    *redis.Client
+	disks    Disks
+	log      *zap.Logger
+	interval time.Duration
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewUploadCleaner(redis *redis.Client, disks Disks, log *zap.Logger, interval time.Duration) *UploadCleaner {
+	return &UploadCleaner{redis: redis, disks: disks, log: log, interval: interval, stop: make(chan struct{})}
+}
+
+// Start 在后台按间隔清理
+func (c *UploadCleaner) Start() {
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.stop:
+				return
+			case <-ticker.C:
+				if _, err := c.Clean(context.Background()); err != nil {
+					c.log.Error("clean expired uploads failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// Stop 停止清理，等待正在进行的清理完成
+func (c *UploadCleaner) Stop(ctx context.Context) error {
+	close(c.stop)
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Clean 清理已过期的分片上传，多实例同时清理时由 ZREM 结果决定由谁处理
+func (c *UploadCleaner) Clean(ctx context.Context) (int, error) {
+	members, err := c.redis.ZRangeByScore(ctx, uploadExpiringKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(time.Now().Unix(), 10),
+	}).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	cleaned := 0
+	var errs []error
+	for _, member := range members {
+		removed, err := c.redis.ZRem(ctx, uploadExpiringKey, member).Result()
+		if err != nil || removed == 0 {
+			continue
+		}
+		diskType, uploadId, _ := strings.Cut(member, ":")
+		if err = removeChunkUpload(ctx, c.redis, c.disks, diskType, uploadId); err != nil {
+			// 清理失败时放回队列，下次重试
+			c.redis.ZAdd(ctx, uploadExpiringKey, &redis.Z{Score: float64(time.Now().Unix()), Member: member})
+			errs = append(errs, err)
+			continue
+		}
+		cleaned++
+	}
+	if cleaned > 0 {
+		c.log.Info("expired uploads cleaned", zap.Int("count", cleaned))
+	}
+	return cleaned, errors.Join(errs...)
+}
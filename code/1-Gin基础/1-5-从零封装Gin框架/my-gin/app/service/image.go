@@ -0,0 +1,102 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"my-gin/app/common/imaging"
+	"my-gin/app/models"
+	"my-gin/config"
+	"my-gin/global"
+	"path"
+	"sort"
+	"strings"
+)
+
+// 去除元数据后保存原图，并按上传策略生成缩略图，缩略图与原图保存在同一目录
+// 任一步骤失败时删除已保存的文件
+func (mediaService *mediaService) putImage(ctx context.Context, policy policy, target uploadTarget, data []byte) (file storedFile, err error) {
+	original := data
+	if !policy.KeepMetadata {
+		if original, err = imaging.Sanitize(data); err != nil {
+			err = imageError(err)
+			return
+		}
+	}
+
+	var img image.Image
+	if len(policy.Variants) > 0 {
+		// 先解码，图片无法识别时不保存任何文件
+		if img, err = imaging.Decode(data); err != nil {
+			err = imageError(err)
+			return
+		}
+	}
+
+	defer func() {
+		if err != nil {
+			mediaService.removeStored(file)
+		}
+	}()
+
+	if err = mediaService.put(ctx, target, bytes.NewReader(original), int64(len(original))); err != nil {
+		return
+	}
+	sum := sha256.Sum256(original)
+	file = storedFile{target: target, size: int64(len(original)), sha256: hex.EncodeToString(sum[:])}
+
+	names := make([]string, 0, len(policy.Variants))
+	for name := range policy.Variants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		variant, variantTarget, buf, e := thumbnail(img, target, name, policy.Variants[name])
+		if e != nil {
+			err = e
+			return
+		}
+		if err = mediaService.put(ctx, variantTarget, buf, int64(buf.Len())); err != nil {
+			return
+		}
+		file.variants = append(file.variants, variant)
+	}
+	return
+}
+
+// 生成缩略图，key 为原图 key 加上缩略图名称，如 local/avatar/xxx_thumb.jpg
+func thumbnail(img image.Image, target uploadTarget, name string, conf config.ImageVariant) (variant models.MediaVariant, variantTarget uploadTarget, buf *bytes.Buffer, err error) {
+	format := conf.Format
+	if format == "" {
+		format = imaging.FormatJpeg
+	}
+	thumb := imaging.Resize(img, conf.Width, conf.Height, conf.Fit)
+	buf = &bytes.Buffer{}
+	if err = imaging.Encode(buf, thumb, format, conf.Quality); err != nil {
+		return
+	}
+
+	variantTarget = target
+	variantTarget.key = strings.TrimSuffix(target.key, path.Ext(target.key)) + "_" + name + imaging.Extension(format)
+	variant = models.MediaVariant{
+		Name:     name,
+		Src:      variantTarget.key,
+		MimeType: imaging.MimeType(format),
+		Width:    thumb.Bounds().Dx(),
+		Height:   thumb.Bounds().Dy(),
+		Size:     int64(buf.Len()),
+	}
+	return
+}
+
+// 图片解码失败时返回的错误
+func imageError(err error) error {
+	if errors.Is(err, imaging.ErrTooLarge) {
+		return global.Errors.UploadDimensionError.WithMsg(fmt.Sprintf("图片像素数超出限制，最多 %d 像素", imaging.MaxPixels)).WithCause(err)
+	}
+	return global.Errors.UploadTypeError.WithMsg("无法识别图片内容").WithCause(err)
+}
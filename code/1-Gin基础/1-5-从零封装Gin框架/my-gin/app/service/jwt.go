@@ -4,16 +4,30 @@ import (
 	"context"
 	"errors"
 	"github.com/dgrijalva/jwt-go"
-	"my-gin/global"
+	"github.com/go-redis/redis/v8"
+	"my-gin/config"
 	"my-gin/utils"
 	"strconv"
 	"time"
 )
 
+// JwtService token 签发、黑名单及用户查询
+type JwtService interface {
+	CreateToken(GuardName string, user JwtUser) (tokenData TokenOutPut, err error, token *jwt.Token)
+	JoinBlackList(ctx context.Context, token *jwt.Token) (err error)
+	IsInBlacklist(ctx context.Context, tokenStr string) bool
+	GetUserInfo(ctx context.Context, GuardName string, id string) (err error, user JwtUser)
+}
+
 type jwtService struct {
+	conf        *config.Store
+	redis       *redis.Client
+	userService UserService
 }
 
-var JwtService = new(jwtService)
+func NewJwtService(conf *config.Store, redis *redis.Client, userService UserService) JwtService {
+	return &jwtService{conf: conf, redis: redis, userService: userService}
+}
 
 // JwtUser 所有需要颁发 token 的用户模型必须实现这个接口
 type JwtUser interface {
@@ -42,18 +56,18 @@ func (jwtService *jwtService) CreateToken(GuardName string, user JwtUser) (token
 		jwt.SigningMethodHS256,
 		CustomClaims{ // 自定义声明
 			StandardClaims: jwt.StandardClaims{
-				ExpiresAt: time.Now().Unix() + global.App.Config.Jwt.JwtTtl, // 设置 token 的过期时间，使用 Unix 时间戳 + 配置的有效期（JwtTtl）
-				Id:        user.GetUid(),                                    // 设置 token 的唯一 ID，通常是用户 ID
-				Issuer:    GuardName,                                        // 设置 token 的颁发者，用于区分不同客户端的 token
-				NotBefore: time.Now().Unix() - 1000,                         // 设置 token 的生效时间，这里设置为当前时间减去 1000 秒（可能是为了提前几秒使用）
+				ExpiresAt: time.Now().Unix() + jwtService.conf.Load().Jwt.JwtTtl, // 设置 token 的过期时间，使用 Unix 时间戳 + 配置的有效期（JwtTtl）
+				Id:        user.GetUid(),                                         // 设置 token 的唯一 ID，通常是用户 ID
+				Issuer:    GuardName,                                             // 设置 token 的颁发者，用于区分不同客户端的 token
+				NotBefore: time.Now().Unix() - 1000,                              // 设置 token 的生效时间，这里设置为当前时间减去 1000 秒（可能是为了提前几秒使用）
 			},
 		},
 	)
 
-	tokenStr, err := token.SignedString([]byte(global.App.Config.Jwt.Secret))
+	tokenStr, err := token.SignedString([]byte(jwtService.conf.Load().Jwt.Secret))
 	tokenData = TokenOutPut{
 		tokenStr,
-		int(global.App.Config.Jwt.JwtTtl),
+		int(jwtService.conf.Load().Jwt.JwtTtl),
 		TokenType,
 	}
 	return
@@ -65,17 +79,17 @@ func (jwtService *jwtService) getBlackListKey(tokenStr string) string {
 }
 
 // JoinBlackList token 加入黑名单
-func (jwtService *jwtService) JoinBlackList(token *jwt.Token) (err error) {
+func (jwtService *jwtService) JoinBlackList(ctx context.Context, token *jwt.Token) (err error) {
 	nowUnix := time.Now().Unix()
 	timer := time.Duration(token.Claims.(*CustomClaims).ExpiresAt-nowUnix) * time.Second
 	// 将 token 剩余时间设置为缓存有效期，并将当前时间作为缓存 value 值
-	err = global.App.Redis.SetNX(context.Background(), jwtService.getBlackListKey(token.Raw), strconv.FormatInt(nowUnix, 10), timer).Err()
+	err = jwtService.redis.SetNX(ctx, jwtService.getBlackListKey(token.Raw), strconv.FormatInt(nowUnix, 10), timer).Err()
 	return
 }
 
 // IsInBlacklist token 是否在黑名单中
-func (jwtService *jwtService) IsInBlacklist(tokenStr string) bool {
-	blackedUnixStr, err := global.App.Redis.Get(context.Background(), jwtService.getBlackListKey(tokenStr)).Result()
+func (jwtService *jwtService) IsInBlacklist(ctx context.Context, tokenStr string) bool {
+	blackedUnixStr, err := jwtService.redis.Get(ctx, jwtService.getBlackListKey(tokenStr)).Result()
 	if err != nil {
 		return false
 	}
@@ -86,17 +100,17 @@ func (jwtService *jwtService) IsInBlacklist(tokenStr string) bool {
 	}
 
 	// JwtBlacklistGracePeriod 为黑名单宽限时间，避免并发请求失效
-	if time.Now().Unix()-blackedUnix < global.App.Config.Jwt.JwtBlacklistGracePeriod {
+	if time.Now().Unix()-blackedUnix < jwtService.conf.Load().Jwt.JwtBlacklistGracePeriod {
 		return false
 	}
 	return true
 }
 
 // GetUserInfo 根据不同客户端 token ，查询不同用户表数据
-func (jwtService *jwtService) GetUserInfo(GuardName string, id string) (err error, user JwtUser) {
+func (jwtService *jwtService) GetUserInfo(ctx context.Context, GuardName string, id string) (err error, user JwtUser) {
 	switch GuardName {
 	case AppGuardName:
-		return UserService.GetUserInfo(id)
+		return jwtService.userService.GetUserInfo(ctx, id)
 	default:
 		err = errors.New("guard " + GuardName + " dose not exist")
 	}
@@ -2,105 +2,540 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"github.com/go-redis/redis/v8"
 	"github.com/jassue/go-storage/storage"
 	uuid "github.com/satori/go.uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"io"
+	"mime/multipart"
+	"my-gin/app/common/metrics"
 	"my-gin/app/common/request"
+	"my-gin/app/common/storage/signed"
+	"my-gin/app/common/tracing"
 	"my-gin/app/models"
+	"my-gin/config"
 	"my-gin/global"
+	"net/url"
 	"path"
 	"strconv"
+	"strings"
 	"time"
 )
 
+// Disks 按名称获取文件系统，未传参时使用默认驱动，驱动未注册时返回错误
+type Disks func(disk ...string) (storage.Storage, error)
+
+// MediaService 媒体文件服务
+type MediaService interface {
+	SaveImage(ctx context.Context, userId string, params request.ImageUpload) (result outPut, err error)
+	SaveFile(ctx context.Context, userId string, params request.FileUpload) (result outPut, err error)
+	ListMedia(ctx context.Context, userId string, params request.MediaList) (list []MediaInfo, total int64, err error)
+	GetMedia(ctx context.Context, userId string, id int64) (info MediaInfo, err error)
+	DeleteMedia(ctx context.Context, userId string, id int64) error
+	GetUrlById(ctx context.Context, id int64, variant string) string
+	SignedUrlById(ctx context.Context, id int64, variant string, userId string, disposition string) (string, error)
+	Open(disk string, key string) (file io.ReadCloser, size int64, err error)
+	InitUpload(ctx context.Context, userId string, params request.UploadInit) (session UploadSession, err error)
+	UploadStatus(ctx context.Context, userId string, uploadId string) (session UploadSession, err error)
+	UploadChunk(ctx context.Context, userId string, uploadId string, index int, r io.Reader) error
+	CompleteUpload(ctx context.Context, userId string, uploadId string) (result outPut, err error)
+	AbortUpload(ctx context.Context, userId string, uploadId string) error
+}
+
 type mediaService struct {
+	conf   *config.Store
+	db     *gorm.DB
+	redis  *redis.Client
+	disks  Disks
+	signer *signed.Signer
+	locker *global.Locker
 }
 
-var MediaService = new(mediaService)
+func NewMediaService(conf *config.Store, db *gorm.DB, redis *redis.Client, disks Disks, signer *signed.Signer, locker *global.Locker) MediaService {
+	return &mediaService{conf: conf, db: db, redis: redis, disks: disks, signer: signer, locker: locker}
+}
 
 type outPut struct {
-	Id   int64  `json:"id"`
-	Path string `json:"path"`
-	Url  string `json:"url"`
+	Id       int64             `json:"id"`
+	Path     string            `json:"path"`
+	Url      string            `json:"url"`
+	Variants map[string]string `json:"variants,omitempty"` // 缩略图名称 => url
+}
+
+// MediaInfo 文件信息，私有文件的 url 为临时访问链接
+type MediaInfo struct {
+	Id         int64             `json:"id"`
+	Name       string            `json:"name"`
+	Business   string            `json:"business"`
+	MimeType   string            `json:"mime_type"`
+	Size       int64             `json:"size"`
+	Sha256     string            `json:"sha256"`
+	Visibility string            `json:"visibility"`
+	Url        string            `json:"url"`
+	Variants   map[string]string `json:"variants,omitempty"` // 缩略图名称 => url
+	CreatedAt  time.Time         `json:"created_at"`
 }
 
 const mediaCacheKeyPre = "media"
 
+// 同一存储对象的去重及删除互斥，锁的有效期
+const mediaObjectLockSeconds = 10
+
 // 文件存储目录
 func (mediaService *mediaService) makeFaceDir(business string) string {
-	return global.App.Config.App.Env + "/" + business
+	return mediaService.conf.Load().App.Env + "/" + business
 }
 
-// HashName 生成文件名称（使用 uuid）
-func (mediaService *mediaService) HashName(fileName string) string {
-	fileSuffix := path.Ext(fileName)
+// HashName 生成文件名称（使用 uuid），后缀由文件内容识别得到，不使用客户端文件名
+func (mediaService *mediaService) HashName(fileSuffix string) string {
 	return uuid.NewV4().String() + fileSuffix
 }
 
-// SaveImage 保存图片（公共读）
-func (mediaService *mediaService) SaveImage(params request.ImageUpload) (result outPut, err error) {
-	file, err := params.Image.Open()
+// SaveImage 保存图片，文件内容需为图片
+func (mediaService *mediaService) SaveImage(ctx context.Context, userId string, params request.ImageUpload) (result outPut, err error) {
+	return mediaService.saveFile(ctx, userId, params.Business, params.Image, params.Visibility, true)
+}
+
+// SaveFile 保存文件，按业务类型的上传策略校验文件类型及大小
+func (mediaService *mediaService) SaveFile(ctx context.Context, userId string, params request.FileUpload) (result outPut, err error) {
+	return mediaService.saveFile(ctx, userId, params.Business, params.File, params.Visibility, false)
+}
+
+// 校验上传策略后保存文件，默认公共读，私有文件返回临时访问链接
+func (mediaService *mediaService) saveFile(ctx context.Context, userId string, business string, header *multipart.FileHeader, visibility string, imageOnly bool) (result outPut, err error) {
+	// viper 读取的策略键名均为小写，存储目录同样使用小写
+	business = strings.ToLower(business)
+	policy, err := uploadPolicy(mediaService.conf.Load().Upload, business)
+	if err != nil {
+		return
+	}
+	if err = policy.checkSize(header.Size); err != nil {
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		err = global.Errors.UploadFailedError.WithCause(err)
+		return
+	}
 	defer file.Close()
+
+	mimeType, err := policy.check(file, imageOnly)
 	if err != nil {
-		err = errors.New("上传失败")
 		return
 	}
 
-	localPrefix := ""
-	// 本地文件存放路径为 storage/app/public，由于在『（五）静态资源处理 & 优雅重启服务器』中，
-	// 配置了静态资源处理路由 router.Static("/storage", "./storage/app/public")
-	// 所以此处不需要将 public/ 存入到 mysql 中，防止后续拼接文件 Url 错误
-	if global.App.Config.Storage.Default == storage.Local {
-		localPrefix = "public" + "/"
+	target := mediaService.uploadTarget(policy, business, visibility, mimeType.Extension())
+	var stored storedFile
+	if policy.processImage(mimeType) {
+		data, e := io.ReadAll(file)
+		if e != nil {
+			err = global.Errors.UploadFailedError.WithCause(e)
+			return
+		}
+		if stored, err = mediaService.putImage(ctx, policy, target, data); err != nil {
+			return
+		}
+	} else if stored, err = mediaService.putFile(ctx, target, file, header.Size); err != nil {
+		return
+	}
+	return mediaService.createMedia(ctx, userId, business, path.Base(header.Filename), mimeType.String(), stored)
+}
+
+// 上传文件的存储位置
+type uploadTarget struct {
+	disk       string
+	visibility string
+	key        string
+}
+
+// 已保存的文件
+type storedFile struct {
+	target   uploadTarget
+	size     int64
+	sha256   string
+	variants []models.MediaVariant
+}
+
+// 按上传策略确定存储驱动及可见性，上传策略配置了可见性时忽略上传参数
+func (mediaService *mediaService) uploadTarget(policy policy, business string, visibility string, fileSuffix string) uploadTarget {
+	if policy.Visibility != "" {
+		visibility = policy.Visibility
+	}
+	if visibility == "" {
+		visibility = models.MediaPublic
+	}
+	diskType := policy.Disk
+	if diskType == "" {
+		diskType = string(mediaService.conf.Load().Storage.Default)
 	}
-	// mediaService.makeFaceDir(params.Business) -> test/business
-	key := mediaService.makeFaceDir(params.Business) + "/" + mediaService.HashName(params.Image.Filename)
-	disk := global.App.Disk()
+	return uploadTarget{
+		disk:       diskType,
+		visibility: visibility,
+		// mediaService.makeFaceDir(business) -> test/business
+		key: mediaService.makeFaceDir(business) + "/" + mediaService.HashName(fileSuffix),
+	}
+}
 
-	// localPrefix+key -> public/local/test/xxx.png
-	// key -> local/test/xxx.png
-	// 存储位置：[/storage/app/public]/local/test/xxx.png
-	// Url 输出：http://localhost:8888[/storage]/local/test/xxx.png
-	err = disk.Put(localPrefix+key, file, params.Image.Size)
+func (mediaService *mediaService) put(ctx context.Context, target uploadTarget, r io.Reader, size int64) error {
+	_, span := tracing.Tracer().Start(ctx, "storage.Put", trace.WithAttributes(
+		attribute.String("storage.disk", target.disk),
+		attribute.String("storage.key", target.key),
+		attribute.Int64("storage.size", size),
+	))
+	disk, err := mediaService.disks(target.disk)
+	if err == nil {
+		err = disk.Put(storageKey(target.disk, target.visibility, target.key), r, size)
+	}
+	tracing.End(span, err)
 	if err != nil {
+		return err
+	}
+	metrics.UploadSizeBytes.WithLabelValues(target.disk).Observe(float64(size))
+	return nil
+}
+
+// 保存文件并计算 SHA-256
+func (mediaService *mediaService) putFile(ctx context.Context, target uploadTarget, r io.Reader, size int64) (stored storedFile, err error) {
+	hash := sha256.New()
+	if err = mediaService.put(ctx, target, io.TeeReader(r, hash), size); err != nil {
 		return
 	}
+	return storedFile{target: target, size: size, sha256: hex.EncodeToString(hash.Sum(nil))}, nil
+}
 
-	image := models.Media{
-		DiskType: string(global.App.Config.Storage.Default),
-		ScrType:  1,
-		Src:      key,
+// 删除已保存的原图及缩略图
+func (mediaService *mediaService) removeStored(stored storedFile) {
+	if stored.target.key == "" {
+		return
 	}
-	err = global.App.DB.Create(&image).Error
+	disk, err := mediaService.disks(stored.target.disk)
 	if err != nil {
 		return
 	}
+	_ = disk.Delete(storageKey(stored.target.disk, stored.target.visibility, stored.target.key))
+	for _, variant := range stored.variants {
+		_ = disk.Delete(storageKey(stored.target.disk, stored.target.visibility, variant.Src))
+	}
+}
+
+// 记录文件信息，已存在内容相同的存储对象时删除本次保存的文件，引用已有的存储对象
+// 业务类型、存储驱动及可见性均相同时才共用存储对象，保证存储路径、缩略图及访问方式一致
+func (mediaService *mediaService) createMedia(ctx context.Context, userId string, business string, name string, mimeType string, stored storedFile) (result outPut, err error) {
+	lock := mediaService.locker.Lock(mediaObjectLockKey(stored.sha256), mediaObjectLockSeconds)
+	if !lock.Block(mediaObjectLockSeconds) {
+		mediaService.removeStored(stored)
+		err = global.Errors.UploadFailedError
+		return
+	}
+	defer lock.Release()
 
-	result = outPut{int64(image.ID.ID), key, disk.Url(key)}
+	media := models.Media{
+		UserId:     parseUserId(userId),
+		Business:   business,
+		DiskType:   stored.target.disk,
+		ScrType:    1,
+		Src:        stored.target.key,
+		Visibility: stored.target.visibility,
+		Name:       name,
+		MimeType:   mimeType,
+		Size:       stored.size,
+		Sha256:     stored.sha256,
+		Variants:   stored.variants,
+	}
+
+	existing := models.Media{}
+	err = mediaService.db.WithContext(ctx).Preload("Variants").
+		Where("sha256 = ? AND business = ? AND disk_type = ? AND visibility = ?", stored.sha256, business, stored.target.disk, stored.target.visibility).
+		First(&existing).Error
+	switch {
+	case err == nil:
+		// 删除本次保存的文件，引用已有的存储对象
+		mediaService.removeStored(stored)
+		stored = storedFile{}
+		media.Src = existing.Src
+		media.Variants = make([]models.MediaVariant, 0, len(existing.Variants))
+		for _, variant := range existing.Variants {
+			variant.ID, variant.MediaId, variant.Timestamp = models.ID{}, 0, models.Timestamp{}
+			media.Variants = append(media.Variants, variant)
+		}
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		mediaService.removeStored(stored)
+		return
+	}
+
+	if err = mediaService.db.WithContext(ctx).Create(&media).Error; err != nil {
+		// 记录写入失败时删除已保存的文件，避免产生孤儿文件
+		mediaService.removeStored(stored)
+		return
+	}
+	result = outPut{Id: int64(media.ID.ID), Path: media.Src, Url: mediaService.mediaUrl(media, media.Src), Variants: mediaService.variantUrls(media)}
 	return
 }
 
-// GetUrlById 通过 id 获取文件 url
-func (mediaService *mediaService) GetUrlById(id int64) string {
+func mediaObjectLockKey(sha256 string) string {
+	return "media_object:" + sha256
+}
+
+// 用户 id 不正确时返回 0，不匹配任何用户的文件
+func parseUserId(userId string) uint {
+	id, _ := strconv.ParseUint(userId, 10, 64)
+	return uint(id)
+}
+
+// 文件在存储中的 key
+// 本地文件按可见性存放在 storage/app/public、storage/app/private，
+// 由于在『（五）静态资源处理 & 优雅重启服务器』中，配置了静态资源处理路由 router.Static("/storage", "./storage/app/public")
+// 所以此处不需要将 public/ 存入到 mysql 中，防止后续拼接文件 Url 错误；其他驱动私有文件以 private/ 为前缀
+// key -> local/test/xxx.png
+// 存储位置：[/storage/app/public]/local/test/xxx.png
+// Url 输出：http://localhost:8888[/storage]/local/test/xxx.png
+func storageKey(diskType string, visibility string, key string) string {
+	if diskType == string(storage.Local) || visibility == models.MediaPrivate {
+		return visibility + "/" + key
+	}
+	return key
+}
+
+// 公开文件返回 url，私有文件返回临时访问链接，src 为原图或缩略图的 key
+// 本地私有文件经由下载接口访问，链接绑定上传者，仅上传者登录后可下载；
+// S3 预签名链接直接访问存储服务，无法绑定用户，只能通过 storage.temporary_url_ttl 限制有效期
+func (mediaService *mediaService) mediaUrl(media models.Media, src string) string {
+	disk, err := mediaService.disks(media.DiskType)
+	if err != nil {
+		return ""
+	}
+	if !media.IsPrivate() {
+		return disk.Url(src)
+	}
+	if media.DiskType == string(storage.Local) && media.UserId != 0 {
+		return mediaService.signer.Sign(url.Values{
+			signed.ParamDisk:   {media.DiskType},
+			signed.ParamKey:    {storageKey(media.DiskType, media.Visibility, src)},
+			signed.ParamUserId: {strconv.FormatUint(uint64(media.UserId), 10)},
+		}, mediaService.temporaryUrlTtl())
+	}
+	temporaryDisk, ok := disk.(signed.Storage)
+	if !ok {
+		return ""
+	}
+	url, err := temporaryDisk.TemporaryUrl(storageKey(media.DiskType, media.Visibility, src), mediaService.temporaryUrlTtl())
+	if err != nil {
+		return ""
+	}
+	return url
+}
+
+// 缩略图名称 => url
+func (mediaService *mediaService) variantUrls(media models.Media) map[string]string {
+	if len(media.Variants) == 0 {
+		return nil
+	}
+	urls := make(map[string]string, len(media.Variants))
+	for _, variant := range media.Variants {
+		urls[variant.Name] = mediaService.mediaUrl(media, variant.Src)
+	}
+	return urls
+}
+
+func (mediaService *mediaService) temporaryUrlTtl() time.Duration {
+	return time.Duration(mediaService.conf.Load().Storage.TemporaryUrlTtl) * time.Second
+}
+
+// 清除缓存的原图及缩略图 url
+func (mediaService *mediaService) forgetUrl(ctx context.Context, media models.Media) {
+	keys := []string{mediaUrlCacheKey(int64(media.ID.ID), "")}
+	for _, variant := range media.Variants {
+		keys = append(keys, mediaUrlCacheKey(int64(media.ID.ID), variant.Name))
+	}
+	mediaService.redis.Del(ctx, keys...)
+}
+
+func mediaUrlCacheKey(id int64, variant string) string {
+	if variant == "" {
+		return mediaCacheKeyPre + strconv.FormatInt(id, 10)
+	}
+	return mediaCacheKeyPre + strconv.FormatInt(id, 10) + ":" + variant
+}
+
+// GetUrlById 通过 id 获取文件 url，variant 为缩略图名称，为空时返回原图 url
+// 私有文件返回临时访问链接，不缓存
+func (mediaService *mediaService) GetUrlById(ctx context.Context, id int64, variant string) string {
 	if id == 0 {
 		return ""
 	}
 
 	var url string
-	cacheKey := mediaCacheKeyPre + strconv.FormatInt(id, 10)
+	cacheKey := mediaUrlCacheKey(id, variant)
 
-	exist := global.App.Redis.Exists(context.Background(), cacheKey).Val()
+	exist := mediaService.redis.Exists(ctx, cacheKey).Val()
 	if exist == 1 {
-		url = global.App.Redis.Get(context.Background(), cacheKey).Val()
+		url = mediaService.redis.Get(ctx, cacheKey).Val()
 	} else {
-		media := models.Media{}
-		err := global.App.DB.First(&media, id).Error
+		media, src, err := mediaService.findMedia(ctx, id, variant)
 		if err != nil {
 			return ""
 		}
-		url = global.App.Disk(media.DiskType).Url(media.Src)
-		global.App.Redis.Set(context.Background(), cacheKey, url, time.Second*3*24*3600)
+		url = mediaService.mediaUrl(media, src)
+		if !media.IsPrivate() {
+			mediaService.redis.Set(ctx, cacheKey, url, time.Second*3*24*3600)
+		}
 	}
 
 	return url
 }
+
+// SignedUrlById 生成签名下载链接，userId 不为空时仅该用户登录后可下载
+// disposition 为 attachment 时浏览器以附件形式下载
+func (mediaService *mediaService) SignedUrlById(ctx context.Context, id int64, variant string, userId string, disposition string) (string, error) {
+	media, src, err := mediaService.findMedia(ctx, id, variant)
+	if err != nil {
+		return "", err
+	}
+	// 私有文件仅上传者可获取下载链接，历史数据未记录上传者时不限制
+	if media.IsPrivate() && media.UserId != 0 && media.UserId != parseUserId(userId) {
+		return "", global.Errors.MediaNotFoundError
+	}
+
+	filename := media.DownloadName()
+	if variant != "" {
+		filename = strings.TrimSuffix(filename, path.Ext(filename)) + "_" + variant + path.Ext(src)
+	}
+	params := url.Values{
+		signed.ParamDisk:     {media.DiskType},
+		signed.ParamKey:      {storageKey(media.DiskType, media.Visibility, src)},
+		signed.ParamFilename: {filename},
+	}
+	if userId != "" {
+		params.Set(signed.ParamUserId, userId)
+	}
+	if disposition != "" {
+		params.Set(signed.ParamDisposition, disposition)
+	}
+	return mediaService.signer.Sign(params, mediaService.temporaryUrlTtl()), nil
+}
+
+// 查询文件及原图或缩略图的 key
+func (mediaService *mediaService) findMedia(ctx context.Context, id int64, variant string) (media models.Media, src string, err error) {
+	if err = mediaService.db.WithContext(ctx).First(&media, id).Error; err != nil {
+		err = global.Errors.MediaNotFoundError
+		return
+	}
+	if variant == "" {
+		return media, media.Src, nil
+	}
+
+	mediaVariant := models.MediaVariant{}
+	if err = mediaService.db.WithContext(ctx).Where("media_id = ? AND name = ?", media.ID.ID, variant).First(&mediaVariant).Error; err != nil {
+		err = global.Errors.VariantNotFoundError
+		return
+	}
+	return media, mediaVariant.Src, nil
+}
+
+// Open 打开文件用于下载
+func (mediaService *mediaService) Open(disk string, key string) (file io.ReadCloser, size int64, err error) {
+	d, err := mediaService.disks(disk)
+	if err != nil {
+		return nil, 0, storage.FileNotFoundErr
+	}
+	if size, err = d.Size(key); err != nil {
+		return
+	}
+	file, err = d.Get(key)
+	return
+}
+
+// ListMedia 分页查询当前用户上传的文件，按上传时间倒序
+func (mediaService *mediaService) ListMedia(ctx context.Context, userId string, params request.MediaList) (list []MediaInfo, total int64, err error) {
+	query := mediaService.db.WithContext(ctx).Model(&models.Media{}).Where("user_id = ?", parseUserId(userId))
+	if params.Business != "" {
+		query = query.Where("business = ?", strings.ToLower(params.Business))
+	}
+	if err = query.Count(&total).Error; err != nil {
+		return
+	}
+
+	var medias []models.Media
+	err = query.Preload("Variants").Order("id desc").Offset(params.Offset()).Limit(params.GetPageSize()).Find(&medias).Error
+	if err != nil {
+		return
+	}
+	list = make([]MediaInfo, 0, len(medias))
+	for _, media := range medias {
+		list = append(list, mediaService.mediaInfo(media))
+	}
+	return
+}
+
+// GetMedia 获取当前用户上传的文件
+func (mediaService *mediaService) GetMedia(ctx context.Context, userId string, id int64) (info MediaInfo, err error) {
+	media, err := mediaService.userMedia(ctx, userId, id)
+	if err != nil {
+		return
+	}
+	return mediaService.mediaInfo(media), nil
+}
+
+// DeleteMedia 删除当前用户上传的文件，存储对象不再被引用时一并删除原图及缩略图
+func (mediaService *mediaService) DeleteMedia(ctx context.Context, userId string, id int64) error {
+	media, err := mediaService.userMedia(ctx, userId, id)
+	if err != nil {
+		return err
+	}
+	if media.Sha256 != "" {
+		lock := mediaService.locker.Lock(mediaObjectLockKey(media.Sha256), mediaObjectLockSeconds)
+		if !lock.Block(mediaObjectLockSeconds) {
+			return global.Errors.MediaBusyError
+		}
+		defer lock.Release()
+	}
+
+	if err = mediaService.db.WithContext(ctx).Select(clause.Associations).Delete(&media).Error; err != nil {
+		return err
+	}
+	mediaService.forgetUrl(ctx, media)
+	var references int64
+	err = mediaService.db.WithContext(ctx).Model(&models.Media{}).
+		Where("disk_type = ? AND src = ?", media.DiskType, media.Src).
+		Count(&references).Error
+	if err != nil {
+		return err
+	}
+	if references == 0 {
+		mediaService.removeStored(storedFile{
+			target:   uploadTarget{disk: media.DiskType, visibility: media.Visibility, key: media.Src},
+			variants: media.Variants,
+		})
+	}
+	return nil
+}
+
+func (mediaService *mediaService) userMedia(ctx context.Context, userId string, id int64) (media models.Media, err error) {
+	err = mediaService.db.WithContext(ctx).Preload("Variants").Where("user_id = ?", parseUserId(userId)).First(&media, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = global.Errors.MediaNotFoundError
+	}
+	return
+}
+
+func (mediaService *mediaService) mediaInfo(media models.Media) MediaInfo {
+	return MediaInfo{
+		Id:         int64(media.ID.ID),
+		Name:       media.Name,
+		Business:   media.Business,
+		MimeType:   media.MimeType,
+		Size:       media.Size,
+		Sha256:     media.Sha256,
+		Visibility: media.Visibility,
+		Url:        mediaService.mediaUrl(media, media.Src),
+		Variants:   mediaService.variantUrls(media),
+		CreatedAt:  media.CreatedAt,
+	}
+}
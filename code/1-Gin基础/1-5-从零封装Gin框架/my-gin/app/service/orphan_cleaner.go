@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/jassue/go-storage/storage"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"my-gin/app/common/storage/s3"
+	"my-gin/app/models"
+	"my-gin/config"
+	"my-gin/global"
+	"sync"
+	"time"
+)
+
+const (
+	orphanCleanLockKey = "storage_gc"
+	// 单次检查的最长时间，超时后其他实例可再次获取锁
+	orphanCleanLockSeconds = 3600
+	// 未配置 storage.gc.grace_period 时的宽限期
+	defaultOrphanGracePeriod = 24 * time.Hour
+	// 每批加载的 Media 记录数
+	orphanMediaBatchSize = 500
+)
+
+// walkableStorage 可遍历文件的存储驱动，本地及 s3 驱动均已实现
+type walkableStorage interface {
+	Walk(prefix string, fn func(key string, modTime time.Time) error) error
+}
+
+// OrphanFile 无 Media 记录引用的文件
+type OrphanFile struct {
+	Disk    string
+	Key     string
+	ModTime time.Time
+}
+
+// OrphanReport 一次检查的结果
+type OrphanReport struct {
+	Scanned int
+	Orphans []OrphanFile
+	Deleted int
+}
+
+// OrphanCleaner 定期比对各存储驱动中的文件与 Media 记录，报告或删除孤儿文件
+// 只检查上传目录（当前环境下公开及私有文件目录），分片上传的临时文件由 UploadCleaner 清理
+type OrphanCleaner struct {
+	conf   *config.Store
+	db     *gorm.DB
+	disks  Disks
+	locker *global.Locker
+	log    *zap.Logger
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewOrphanCleaner(conf *config.Store, db *gorm.DB, disks Disks, locker *global.Locker, log *zap.Logger) *OrphanCleaner {
+	return &OrphanCleaner{conf: conf, db: db, disks: disks, locker: locker, log: log}
+}
+
+// Start 在后台按间隔检查
+func (c *OrphanCleaner) Start(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := c.Clean(ctx); err != nil && !errors.Is(err, context.Canceled) {
+					c.log.Error("clean orphan files failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// Stop 停止检查，中断正在进行的检查并等待其退出
+func (c *OrphanCleaner) Stop(ctx context.Context) error {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Clean 检查孤儿文件，storage.gc.delete 开启时删除，其他实例正在检查时直接返回
+func (c *OrphanCleaner) Clean(ctx context.Context) (report OrphanReport, err error) {
+	lock := c.locker.Lock(orphanCleanLockKey, orphanCleanLockSeconds)
+	if !lock.Get() {
+		c.log.Info("orphan files check is running on another instance, skipped")
+		return
+	}
+	defer lock.Release()
+
+	conf := c.conf.Load()
+	gracePeriod := defaultOrphanGracePeriod
+	if conf.Storage.Gc.GracePeriod > 0 {
+		gracePeriod = time.Duration(conf.Storage.Gc.GracePeriod) * time.Second
+	}
+	before := time.Now().Add(-gracePeriod)
+
+	var errs []error
+	for _, diskName := range c.diskNames(conf) {
+		if err = c.cleanDisk(ctx, conf, diskName, before, &report); err != nil {
+			if errors.Is(err, context.Canceled) {
+				return
+			}
+			errs = append(errs, err)
+		}
+	}
+	err = errors.Join(errs...)
+	c.log.Info("orphan files checked",
+		zap.Int("scanned", report.Scanned),
+		zap.Int("orphans", len(report.Orphans)),
+		zap.Int("deleted", report.Deleted),
+	)
+	return
+}
+
+func (c *OrphanCleaner) cleanDisk(ctx context.Context, conf *config.Configuration, diskName string, before time.Time, report *OrphanReport) error {
+	disk, err := c.disks(diskName)
+	if err != nil {
+		return err
+	}
+	walker, ok := disk.(walkableStorage)
+	if !ok {
+		return nil
+	}
+	referenced, err := c.referencedKeys(ctx, diskName)
+	if err != nil {
+		return err
+	}
+
+	for _, prefix := range uploadPrefixes(diskName, conf.App.Env) {
+		err = walker.Walk(prefix, func(key string, modTime time.Time) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			report.Scanned++
+			if referenced[key] || modTime.After(before) {
+				return nil
+			}
+
+			report.Orphans = append(report.Orphans, OrphanFile{Disk: diskName, Key: key, ModTime: modTime})
+			c.log.Warn("orphan file found", zap.String("disk", diskName), zap.String("key", key), zap.Time("mod_time", modTime))
+			if !conf.Storage.Gc.Delete {
+				return nil
+			}
+			if err := disk.Delete(key); err != nil {
+				c.log.Error("delete orphan file failed", zap.String("disk", diskName), zap.String("key", key), zap.Error(err))
+				return nil
+			}
+			report.Deleted++
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 已启用的存储驱动
+func (c *OrphanCleaner) diskNames(conf *config.Configuration) []string {
+	names := []string{string(storage.Local)}
+	if conf.Storage.Disks.S3.Endpoint != "" {
+		names = append(names, string(s3.DiskName))
+	}
+	return names
+}
+
+// Media 记录引用的原图及缩略图在存储中的 key
+func (c *OrphanCleaner) referencedKeys(ctx context.Context, diskName string) (map[string]bool, error) {
+	keys := make(map[string]bool)
+	var medias []models.Media
+	err := c.db.WithContext(ctx).Preload("Variants").Where("disk_type = ?", diskName).
+		FindInBatches(&medias, orphanMediaBatchSize, func(tx *gorm.DB, batch int) error {
+			for _, media := range medias {
+				keys[storageKey(diskName, media.Visibility, media.Src)] = true
+				for _, variant := range media.Variants {
+					keys[storageKey(diskName, media.Visibility, variant.Src)] = true
+				}
+			}
+			return nil
+		}).Error
+	return keys, err
+}
+
+// 上传目录，文件 key 以当前环境名开头，见 makeFaceDir
+func uploadPrefixes(diskName string, env string) []string {
+	return []string{
+		storageKey(diskName, models.MediaPublic, env+"/"),
+		storageKey(diskName, models.MediaPrivate, env+"/"),
+	}
+}
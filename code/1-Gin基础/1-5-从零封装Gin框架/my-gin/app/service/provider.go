@@ -0,0 +1,6 @@
+package service
+
+import "github.com/google/wire"
+
+// ProviderSet 服务层 provider
+var ProviderSet = wire.NewSet(NewUserService, NewJwtService, NewMediaService)
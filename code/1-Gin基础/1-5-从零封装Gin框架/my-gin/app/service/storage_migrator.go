@@ -0,0 +1,295 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"github.com/jassue/go-storage/storage"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"io"
+	"my-gin/app/models"
+	"sync"
+)
+
+// 未指定 BatchSize 时每批处理的 Media 记录数
+const defaultMigrateBatchSize = 100
+
+// MigrateOptions 存储迁移参数
+type MigrateOptions struct {
+	From      string // 源存储驱动
+	To        string // 目标存储驱动
+	Workers   int    // 并行复制的文件数
+	BatchSize int    // 每批处理的 Media 记录数
+	DryRun    bool   // 仅统计需迁移的文件，不复制、不更新记录
+}
+
+// MigrateReport 存储迁移结果
+type MigrateReport struct {
+	Records int   // 需迁移的 Media 记录数
+	Objects int   // 需迁移的存储对象数（含缩略图），共用存储对象的记录只计一次
+	Bytes   int64 // 需迁移的存储对象大小
+	Copied  int   // 复制的存储对象数
+	Skipped int   // 目标存储中已存在且校验一致的存储对象数
+	Updated int   // 更新的 Media 记录数
+	Failed  []MigrateFailure
+}
+
+// MigrateFailure 迁移失败的存储对象，对应的 Media 记录保持不变，重新执行时重试
+type MigrateFailure struct {
+	Key string
+	Err error
+}
+
+// StorageMigrator 将 Media 记录引用的文件从一个存储驱动迁移到另一个存储驱动
+// 文件复制并校验 SHA-256 后才更新记录，中断后重新执行时跳过目标存储中已存在且内容一致的文件
+// 源文件保留，更新记录后由孤儿文件清理删除
+type StorageMigrator struct {
+	db    *gorm.DB
+	redis *redis.Client
+	disks Disks
+	log   *zap.Logger
+}
+
+func NewStorageMigrator(db *gorm.DB, redis *redis.Client, disks Disks, log *zap.Logger) *StorageMigrator {
+	return &StorageMigrator{db: db, redis: redis, disks: disks, log: log}
+}
+
+// 待迁移的存储对象及引用它的 Media 记录
+type migrateObject struct {
+	visibility string
+	src        string
+	checksum   string
+	size       int64
+	variants   []models.MediaVariant
+	medias     []models.Media
+	err        error
+}
+
+// Migrate 按 id 顺序分批迁移，每批文件并行复制，复制成功的存储对象对应的记录批量更新
+// 复制失败的记录保持不变，记入 Failed 后继续迁移后续批次
+func (m *StorageMigrator) Migrate(ctx context.Context, opts MigrateOptions) (report MigrateReport, err error) {
+	if opts.From == opts.To {
+		return report, errors.New("source and target disk are the same")
+	}
+	from, err := m.disks(opts.From)
+	if err != nil {
+		return report, err
+	}
+	to, err := m.disks(opts.To)
+	if err != nil {
+		return report, err
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultMigrateBatchSize
+	}
+
+	var lastId uint
+	for {
+		var medias []models.Media
+		err = m.db.WithContext(ctx).Preload("Variants").
+			Where("disk_type = ? AND id > ?", opts.From, lastId).
+			Order("id").Limit(opts.BatchSize).Find(&medias).Error
+		if err != nil || len(medias) == 0 {
+			return
+		}
+		lastId = medias[len(medias)-1].ID.ID
+
+		objects := groupMigrateObjects(medias)
+		report.Records += len(medias)
+		report.Objects += len(objects)
+		for _, object := range objects {
+			report.Bytes += object.size
+			for _, variant := range object.variants {
+				report.Objects++
+				report.Bytes += variant.Size
+			}
+		}
+		if opts.DryRun {
+			continue
+		}
+
+		m.copyObjects(ctx, from, to, opts, objects, &report)
+		if err = ctx.Err(); err != nil {
+			return
+		}
+		if err = m.updateMedias(ctx, opts.To, objects, &report); err != nil {
+			return
+		}
+		m.log.Info("storage migrate batch done",
+			zap.Uint("last_id", lastId),
+			zap.Int("updated", report.Updated),
+			zap.Int("failed", len(report.Failed)),
+		)
+	}
+}
+
+// 按存储对象分组，内容相同的文件共用存储对象，只需复制一次
+func groupMigrateObjects(medias []models.Media) []*migrateObject {
+	var objects []*migrateObject
+	index := make(map[string]*migrateObject)
+	for _, media := range medias {
+		object, ok := index[media.Src]
+		if !ok {
+			object = &migrateObject{
+				visibility: media.Visibility,
+				src:        media.Src,
+				checksum:   media.Sha256,
+				size:       media.Size,
+				variants:   media.Variants,
+			}
+			index[media.Src] = object
+			objects = append(objects, object)
+		}
+		object.medias = append(object.medias, media)
+	}
+	return objects
+}
+
+func (m *StorageMigrator) copyObjects(ctx context.Context, from storage.Storage, to storage.Storage, opts MigrateOptions, objects []*migrateObject, report *MigrateReport) {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	jobs := make(chan *migrateObject)
+	for i := 0; i < max(opts.Workers, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for object := range jobs {
+				copied, skipped, err := m.copyObject(from, to, opts, object)
+				mu.Lock()
+				report.Copied += copied
+				report.Skipped += skipped
+				if err != nil {
+					object.err = err
+					report.Failed = append(report.Failed, MigrateFailure{Key: object.src, Err: err})
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, object := range objects {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- object
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// 复制原图及缩略图
+func (m *StorageMigrator) copyObject(from storage.Storage, to storage.Storage, opts MigrateOptions, object *migrateObject) (copied int, skipped int, err error) {
+	keys := []struct{ src, checksum string }{{object.src, object.checksum}}
+	for _, variant := range object.variants {
+		keys = append(keys, struct{ src, checksum string }{variant.Src, ""})
+	}
+	for _, key := range keys {
+		ok, err := copyFile(from, to,
+			storageKey(opts.From, object.visibility, key.src),
+			storageKey(opts.To, object.visibility, key.src),
+			key.checksum,
+		)
+		if err != nil && key.src != object.src {
+			err = fmt.Errorf("variant %s: %w", key.src, err)
+		}
+		if err != nil {
+			return copied, skipped, err
+		}
+		if ok {
+			copied++
+		} else {
+			skipped++
+		}
+	}
+	return
+}
+
+// 复制文件并校验 SHA-256，checksum 为空时以源文件计算，目标文件已存在且一致时跳过并返回 false
+func copyFile(from storage.Storage, to storage.Storage, srcKey string, dstKey string, checksum string) (bool, error) {
+	if checksum == "" {
+		sum, err := fileSha256(from, srcKey)
+		if err != nil {
+			return false, err
+		}
+		checksum = sum
+	}
+	if sum, err := fileSha256(to, dstKey); err == nil && sum == checksum {
+		return false, nil
+	}
+
+	size, err := from.Size(srcKey)
+	if err != nil {
+		return false, err
+	}
+	reader, err := from.Get(srcKey)
+	if err != nil {
+		return false, err
+	}
+	defer reader.Close()
+	hash := sha256.New()
+	if err = to.Put(dstKey, io.TeeReader(reader, hash), size); err != nil {
+		return false, err
+	}
+	if hex.EncodeToString(hash.Sum(nil)) != checksum {
+		_ = to.Delete(dstKey)
+		return false, errors.New("source checksum mismatch")
+	}
+	if sum, err := fileSha256(to, dstKey); err != nil || sum != checksum {
+		_ = to.Delete(dstKey)
+		return false, errors.New("target checksum mismatch")
+	}
+	return true, nil
+}
+
+func fileSha256(disk storage.Storage, key string) (string, error) {
+	reader, err := disk.Get(key)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+	hash := sha256.New()
+	if _, err = io.Copy(hash, reader); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// 更新复制成功的记录，并清除缓存的 url
+func (m *StorageMigrator) updateMedias(ctx context.Context, diskType string, objects []*migrateObject, report *MigrateReport) error {
+	var ids []uint
+	var medias []models.Media
+	for _, object := range objects {
+		if object.err != nil {
+			continue
+		}
+		for _, media := range object.medias {
+			ids = append(ids, media.ID.ID)
+			medias = append(medias, media)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	// Src 与存储驱动无关，迁移后保持不变
+	result := m.db.WithContext(ctx).Model(&models.Media{}).Where("id IN ?", ids).Update("disk_type", diskType)
+	if result.Error != nil {
+		return result.Error
+	}
+	report.Updated += int(result.RowsAffected)
+
+	if m.redis != nil {
+		keys := make([]string, 0, len(medias))
+		for _, media := range medias {
+			keys = append(keys, mediaUrlCacheKey(int64(media.ID.ID), ""))
+			for _, variant := range media.Variants {
+				keys = append(keys, mediaUrlCacheKey(int64(media.ID.ID), variant.Name))
+			}
+		}
+		m.redis.Del(ctx, keys...)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"fmt"
+	"github.com/gabriel-vasile/mimetype"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"my-gin/app/common/imaging"
+	"my-gin/config"
+	"my-gin/global"
+	"strings"
+)
+
+// policy 业务类型对应的上传策略
+type policy struct {
+	config.UploadPolicy
+}
+
+// 获取业务类型的上传策略，未配置的业务类型不允许上传
+func uploadPolicy(upload config.Upload, business string) (policy, error) {
+	uploadPolicy, ok := upload.Policies[business]
+	if !ok {
+		return policy{}, global.Errors.UploadBusinessError.WithMsg(fmt.Sprintf("不支持的上传业务类型：%s", business))
+	}
+	return policy{UploadPolicy: uploadPolicy}, nil
+}
+
+func (p policy) checkSize(size int64) error {
+	if p.MaxSize > 0 && size > p.MaxSize {
+		return global.Errors.UploadSizeError.WithMsg(fmt.Sprintf("文件大小超出限制，最大 %s", formatSize(p.MaxSize)))
+	}
+	return nil
+}
+
+// 按文件内容识别 MIME 类型并校验，imageOnly 为 true 时仅允许图片
+// 校验完成后文件读取位置重置到开头
+func (p policy) check(file io.ReadSeeker, imageOnly bool) (*mimetype.MIME, error) {
+	mimeType, err := mimetype.DetectReader(file)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	isImage := strings.HasPrefix(mimeType.String(), "image/")
+	if imageOnly && !isImage {
+		return nil, global.Errors.UploadTypeError.WithMsg(fmt.Sprintf("文件类型 %s 不是图片", mimeType.String()))
+	}
+	if !p.allowType(mimeType) {
+		return nil, global.Errors.UploadTypeError.WithMsg(fmt.Sprintf("文件类型 %s 不允许，允许的类型：%s", mimeType.String(), strings.Join(p.Types, ", ")))
+	}
+
+	if isImage && (p.MaxWidth > 0 || p.MaxHeight > 0) {
+		imageConfig, _, err := image.DecodeConfig(file)
+		if err != nil {
+			return nil, global.Errors.UploadDimensionError.WithMsg("无法识别图片尺寸")
+		}
+		if (p.MaxWidth > 0 && imageConfig.Width > p.MaxWidth) || (p.MaxHeight > 0 && imageConfig.Height > p.MaxHeight) {
+			return nil, global.Errors.UploadDimensionError.WithMsg(fmt.Sprintf("图片尺寸超出限制，最大 %dx%d", p.MaxWidth, p.MaxHeight))
+		}
+		if _, err = file.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+	return mimeType, nil
+}
+
+// 是否需要处理图片：去除元数据或生成缩略图
+func (p policy) processImage(mimeType *mimetype.MIME) bool {
+	return imaging.Supported(mimeType.String()) && (!p.KeepMetadata || len(p.Variants) > 0)
+}
+
+// 只匹配识别出的类型及其别名，不匹配父类型，避免 text/plain 放行 text/html
+func (p policy) allowType(mimeType *mimetype.MIME) bool {
+	for _, allowed := range p.Types {
+		if prefix, ok := strings.CutSuffix(allowed, "/*"); ok {
+			if strings.HasPrefix(mimeType.String(), prefix+"/") {
+				return true
+			}
+			continue
+		}
+		if mimeType.Is(allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatSize(size int64) string {
+	switch {
+	case size >= 1<<20 && size%(1<<20) == 0:
+		return fmt.Sprintf("%dMB", size>>20)
+	case size >= 1<<10 && size%(1<<10) == 0:
+		return fmt.Sprintf("%dKB", size>>10)
+	}
+	return fmt.Sprintf("%dB", size)
+}
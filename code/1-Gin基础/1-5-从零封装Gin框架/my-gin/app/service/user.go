@@ -1,7 +1,8 @@
 package service
 
 import (
-	"errors"
+	"context"
+	"gorm.io/gorm"
 	"my-gin/app/common/request"
 	"my-gin/app/models"
 	"my-gin/global"
@@ -9,39 +10,49 @@ import (
 	"strconv"
 )
 
+// UserService 用户服务
+type UserService interface {
+	Register(ctx context.Context, params request.Register) (err error, user models.User)
+	Login(ctx context.Context, param request.Login) (err error, user *models.User)
+	GetUserInfo(ctx context.Context, id string) (err error, user models.User)
+}
+
 type userService struct {
+	db *gorm.DB
 }
 
-var UserService = new(userService)
+func NewUserService(db *gorm.DB) UserService {
+	return &userService{db: db}
+}
 
 // Register 编写用户注册逻辑
-func (userService *userService) Register(params request.Register) (err error, user models.User) {
-	var result = global.App.DB.Where("mobile = ?", params.Mobile).Select("id").First(&models.User{})
+func (userService *userService) Register(ctx context.Context, params request.Register) (err error, user models.User) {
+	var result = userService.db.WithContext(ctx).Where("mobile = ?", params.Mobile).Select("id").First(&models.User{})
 	if result.RowsAffected != 0 {
-		err = errors.New("手机号已经存在")
+		err = global.Errors.MobileExistsError
 		return
 	}
 
 	user = models.User{Name: params.Name, Mobile: params.Mobile, Password: utils.BcryptMake([]byte(params.Password))}
-	err = global.App.DB.Create(&user).Error
+	err = userService.db.WithContext(ctx).Create(&user).Error
 	return
 }
 
 // Login 编写用户登陆逻辑
-func (userService *userService) Login(param request.Login) (err error, user *models.User) {
-	err = global.App.DB.Where("mobile = ?", param.Mobile).First(&user).Error
+func (userService *userService) Login(ctx context.Context, param request.Login) (err error, user *models.User) {
+	err = userService.db.WithContext(ctx).Where("mobile = ?", param.Mobile).First(&user).Error
 	if err != nil || !utils.BcryptMakeCheck([]byte(param.Password), user.Password) {
-		err = errors.New("用户名不存在或者密码错误")
+		err = global.Errors.LoginError
 	}
 	return
 }
 
 // GetUserInfo 获取用户信息
-func (userService *userService) GetUserInfo(id string) (err error, user models.User) {
+func (userService *userService) GetUserInfo(ctx context.Context, id string) (err error, user models.User) {
 	intId, err := strconv.Atoi(id)
-	err = global.App.DB.First(&user, intId).Error
+	err = userService.db.WithContext(ctx).First(&user, intId).Error
 	if err != nil {
-		err = errors.New("数据不存在")
+		err = global.Errors.UserNotFoundError.WithCause(err)
 	}
 	return
 }
@@ -0,0 +1,25 @@
+package bootstrap
+
+import (
+	"my-gin/app/common/alert"
+	"my-gin/global"
+	"time"
+)
+
+func InitializeAlert() *alert.Dispatcher {
+	alertConfig := global.App.Config().Alert
+	logger := global.App.ModuleLog("alert")
+
+	notifiers := []alert.Notifier{alert.LogNotifier{Logger: logger}}
+	if alertConfig.WebhookUrl != "" {
+		notifiers = append(notifiers, alert.NewWebhookNotifier(alertConfig.WebhookUrl))
+	}
+
+	return alert.NewDispatcher(logger, alert.Options{
+		DedupWindow:  time.Duration(alertConfig.DedupWindow) * time.Second,
+		MaxPerMinute: alertConfig.MaxPerMinute,
+		QueueSize:    alertConfig.QueueSize,
+		Workers:      alertConfig.Workers,
+		Retry:        alertConfig.Retry,
+	}, notifiers...)
+}
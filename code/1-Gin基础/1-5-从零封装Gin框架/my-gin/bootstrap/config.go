@@ -4,11 +4,29 @@ import (
 	"fmt"
 	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"my-gin/config"
 	"my-gin/global"
+	"my-gin/utils"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+const (
+	// 环境变量前缀，如 APP_DATABASE_PASSWORD 覆盖 database.password
+	configEnvPrefix = "APP"
+	// 以该后缀结尾的配置项从文件读取对应配置，如 password_file 覆盖 password
+	configFileSuffix = "_file"
 )
 
 func InitializeConfig() *viper.Viper {
+	// 本地开发时从 .env 加载环境变量，已设置的环境变量优先
+	if err := utils.LoadDotEnv(".env"); err != nil {
+		panic(fmt.Errorf("read .env failed: %s \n", err))
+	}
+
 	// 设置配置文件路径
 	config := "config.yaml"
 	// 生产环境中可以通过环境变量来配置文件路径
@@ -16,28 +34,163 @@ func InitializeConfig() *viper.Viper {
 		config = configEnv
 	}
 
-	// 初始化 viper
+	v, files, err := loadConfig(config)
+	if err != nil {
+		panic(fmt.Errorf("read config failed: %s \n", err))
+	}
+
+	// 将 viper 读取到的数据校验后作为初始配置快照，配置不合法时拒绝启动
+	conf, err := decodeConfig(v)
+	if err != nil {
+		panic(err)
+	}
+	global.App.ConfigStore.Swap(conf)
+	global.App.ConfigViper = v
+
+	// 监听基础配置及环境配置文件，任一变化时重新合并并设置回调函数
+	for _, file := range files {
+		watcher := viper.New()
+		watcher.SetConfigFile(file)
+		watcher.WatchConfig()
+		watcher.OnConfigChange(func(in fsnotify.Event) {
+			reloadConfig(config, in.Name)
+		})
+	}
+
+	return v
+}
+
+// 重载配置，校验通过后整体替换配置快照，不合法或使用了未注册的存储驱动时保留当前配置
+func reloadConfig(file string, changed string) {
+	reloaded, _, err := loadConfig(file)
+	if err == nil {
+		var conf *config.Configuration
+		if conf, err = decodeConfig(reloaded); err == nil && storageInitialized.Load() {
+			err = checkDisks(conf)
+		}
+		if err == nil {
+			global.App.ConfigViper = reloaded
+			global.App.ConfigStore.Swap(conf)
+			logConfigReload(changed, nil)
+			return
+		}
+	}
+	logConfigReload(changed, err)
+}
+
+func logConfigReload(changed string, err error) {
+	// 日志尚未初始化时输出到标准输出
+	if global.App.Log == nil {
+		if err != nil {
+			fmt.Println("config reload rejected:", changed, err)
+			return
+		}
+		fmt.Println("config file changed:", changed)
+		return
+	}
+	log := global.App.ModuleLog(global.LogModuleConfig)
+	if err != nil {
+		log.Error("config reload rejected, keep current config", zap.String("file", changed), zap.Error(err))
+		return
+	}
+	log.Info("config reloaded", zap.String("file", changed))
+}
+
+// 解析并校验配置，返回新的配置快照
+func decodeConfig(v *viper.Viper) (*config.Configuration, error) {
+	conf := &config.Configuration{}
+	if err := v.Unmarshal(conf); err != nil {
+		return nil, err
+	}
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+	return conf, nil
+}
+
+// 按优先级合并配置：环境变量 > config.<env>.yaml > 基础配置文件，返回参与合并的文件
+func loadConfig(file string) (*viper.Viper, []string, error) {
 	v := viper.New()
-	v.SetConfigFile(config)
+	v.SetConfigFile(file)
 	v.SetConfigType("yaml")
 	if err := v.ReadInConfig(); err != nil {
-		panic(fmt.Errorf("read config failed: %s \n", err))
+		return nil, nil, err
 	}
+	files := []string{file}
 
-	// 监听配置文件，并设置回调函数
-	v.WatchConfig()
-	v.OnConfigChange(func(in fsnotify.Event) {
-		fmt.Println("config file changed:", in.Name)
-		// 重载配置
-		if err := v.Unmarshal(&global.App.Config); err != nil {
-			fmt.Println(err)
+	v.SetEnvPrefix(configEnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+	secretKeys := map[string]bool{}
+	bindConfigEnvs(v, "", reflect.TypeOf(config.Configuration{}), secretKeys)
+
+	// app.env 可由环境变量 APP_APP_ENV 指定
+	if env := v.GetString("app.env"); env != "" {
+		overlay := overlayConfigFile(file, env)
+		if exists, _ := utils.PathExists(overlay); exists {
+			f, err := os.Open(overlay)
+			if err != nil {
+				return nil, nil, err
+			}
+			err = v.MergeConfig(f)
+			_ = f.Close()
+			if err != nil {
+				return nil, nil, fmt.Errorf("merge %s: %w", overlay, err)
+			}
+			files = append(files, overlay)
 		}
-	})
+	}
 
-	// 将 viper 读取到的数据赋值给全局变量
-	if err := v.Unmarshal(&global.App.Config); err != nil {
-		fmt.Println(err)
+	if err := readSecretFiles(v, secretKeys); err != nil {
+		return nil, nil, err
 	}
+	return v, files, nil
+}
 
-	return v
+// config.yaml -> config.<env>.yaml
+func overlayConfigFile(file string, env string) string {
+	ext := filepath.Ext(file)
+	return strings.TrimSuffix(file, ext) + "." + env + ext
+}
+
+// 为每个配置项绑定环境变量，未出现在配置文件中的配置项同样可以通过环境变量设置
+// 字符串配置项额外绑定 xxx_file，记录在 secretKeys 中
+func bindConfigEnvs(v *viper.Viper, prefix string, t reflect.Type, secretKeys map[string]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("mapstructure"), ",")[0]
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		key := prefix + name
+
+		if field.Type.Kind() == reflect.Struct {
+			bindConfigEnvs(v, key+".", field.Type, secretKeys)
+			continue
+		}
+		_ = v.BindEnv(key)
+		if field.Type.Kind() == reflect.String {
+			secretKeys[key] = true
+			_ = v.BindEnv(key + configFileSuffix)
+		}
+	}
+}
+
+// 读取 xxx_file 指定的文件内容作为 xxx 的值
+func readSecretFiles(v *viper.Viper, secretKeys map[string]bool) error {
+	for key := range secretKeys {
+		filename := v.GetString(key + configFileSuffix)
+		if filename == "" {
+			continue
+		}
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			return fmt.Errorf("read %s%s: %w", key, configFileSuffix, err)
+		}
+		v.Set(key, strings.TrimRight(string(content), "\r\n"))
+	}
+	return nil
 }
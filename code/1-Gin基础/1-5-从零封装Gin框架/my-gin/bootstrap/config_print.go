@@ -0,0 +1,59 @@
+package bootstrap
+
+import (
+	"gopkg.in/yaml.v3"
+	"io"
+	"my-gin/global"
+	"my-gin/utils"
+	"strings"
+)
+
+// 除日志脱敏字段外，按后缀识别的密钥类配置项
+var secretConfigSuffixes = []string{"password", "secret", "token", "secret_key", "webhook_url"}
+
+// PrintConfig 输出合并后的生效配置，密钥类配置项脱敏，需在 InitializeConfig 后调用
+func PrintConfig(w io.Writer) error {
+	var node yaml.Node
+	if err := node.Encode(global.App.Config()); err != nil {
+		return err
+	}
+
+	redactor, _ := utils.NewRedactor(global.App.Config().Log.Redact.Fields, nil)
+	maskConfigNode(&node, redactor)
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	defer encoder.Close()
+	return encoder.Encode(&node)
+}
+
+func maskConfigNode(node *yaml.Node, redactor *utils.Redactor) {
+	if node.Kind != yaml.MappingNode {
+		for _, child := range node.Content {
+			maskConfigNode(child, redactor)
+		}
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if value.Kind == yaml.ScalarNode && value.Value != "" && isSecretConfigKey(key.Value, redactor) {
+			value.Value = utils.RedactMask
+			value.Tag = "!!str"
+			value.Style = 0
+			continue
+		}
+		maskConfigNode(value, redactor)
+	}
+}
+
+func isSecretConfigKey(key string, redactor *utils.Redactor) bool {
+	if redactor.IsSensitive(key) {
+		return true
+	}
+	for _, suffix := range secretConfigSuffixes {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package bootstrap
+
+import (
+	"my-gin/config"
+	"my-gin/global"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// 以仓库中的 config.yaml 为基础，替换部分内容后写入临时目录
+func writeTestConfig(t *testing.T, file string, replacements ...string) {
+	t.Helper()
+	base, err := os.ReadFile("../config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(base)
+	for i := 0; i+1 < len(replacements); i += 2 {
+		if !strings.Contains(content, replacements[i]) {
+			t.Fatalf("config.yaml does not contain %q", replacements[i])
+		}
+		content = strings.Replace(content, replacements[i], replacements[i+1], 1)
+	}
+	if err = os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReloadConfigRejectsInvalid(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	writeTestConfig(t, file)
+	v, _, err := loadConfig(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, err := decodeConfig(v)
+	if err != nil {
+		t.Fatalf("config.yaml is invalid: %v", err)
+	}
+
+	store := global.App.ConfigStore
+	global.App.ConfigStore = config.NewStore(current)
+	t.Cleanup(func() { global.App.ConfigStore = store })
+	notified := 0
+	global.App.ConfigStore.Subscribe(func(_, _ *config.Configuration) { notified++ })
+
+	tests := []struct {
+		name         string
+		replacements []string
+	}{
+		{"invalid log level", []string{"level: info # 日志等级", "level: verbose"}},
+		{"invalid error format", []string{"error_format: legacy", "error_format: xml"}},
+		{"invalid module level", []string{"    db: info", "    db: loud"}},
+		{"cleanup interval exceeds upload ttl", []string{"cleanup_interval: 600", "cleanup_interval: 90000"}},
+		{"malformed yaml", []string{"app: # 基本应用配置", "app: [\n"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeTestConfig(t, file, tt.replacements...)
+			reloadConfig(file, file)
+			if global.App.ConfigStore.Load() != current {
+				t.Error("invalid config replaced the current config")
+			}
+		})
+	}
+	if notified != 0 {
+		t.Errorf("subscribers notified %d times for rejected configs", notified)
+	}
+
+	// 合法的变更正常生效
+	writeTestConfig(t, file, "error_format: legacy", "error_format: problem")
+	reloadConfig(file, file)
+	if got := global.App.ConfigStore.Load().App.ErrorFormat; got != config.ErrorFormatProblem {
+		t.Errorf("error_format = %q after reload, want %q", got, config.ErrorFormatProblem)
+	}
+	if notified != 1 {
+		t.Errorf("subscribers notified %d times, want 1", notified)
+	}
+}
@@ -1,6 +1,8 @@
 package bootstrap
 
 import (
+	"errors"
+	"fmt"
 	"go.uber.org/zap"
 	"gopkg.in/natefinch/lumberjack.v2"
 	"gorm.io/driver/mysql"
@@ -8,6 +10,7 @@ import (
 	"gorm.io/gorm/logger"
 	"io"
 	"log"
+	"my-gin/app/common/tracing"
 	"my-gin/app/models"
 	"my-gin/global"
 	"os"
@@ -15,9 +18,10 @@ import (
 	"time"
 )
 
-func InitializeDB() *gorm.DB {
+// InitializeDB 未配置数据库、连接或迁移失败时返回错误
+func InitializeDB() (*gorm.DB, error) {
 	// 根据驱动配置进行初始化
-	switch global.App.Config.Database.Driver {
+	switch global.App.Config().Database.Driver {
 	case "mysql":
 		return initMysqlGorm()
 	default:
@@ -25,10 +29,10 @@ func InitializeDB() *gorm.DB {
 	}
 }
 
-func initMysqlGorm() *gorm.DB {
-	dbConfig := global.App.Config.Database
+func initMysqlGorm() (*gorm.DB, error) {
+	dbConfig := global.App.Config().Database
 	if dbConfig.Database == "" {
-		return nil
+		return nil, errors.New("database.database is not configured")
 	}
 
 	dsn := dbConfig.UserName + ":" + dbConfig.Password + "@tcp(" + dbConfig.Host + ":" + strconv.Itoa(dbConfig.Port) + ")/" +
@@ -47,26 +51,33 @@ func initMysqlGorm() *gorm.DB {
 		DisableForeignKeyConstraintWhenMigrating: true,            // 禁用自动创建外键约束
 		Logger:                                   getGormLogger(), // 使用自定义 logger
 	}); err != nil {
-		global.App.Log.Error("mysql connect failed, err:", zap.Any("err", err))
-		return nil
+		global.App.ModuleLog(global.LogModuleDB).Error("mysql connect failed, err:", zap.Any("err", err))
+		return nil, fmt.Errorf("mysql connect: %w", err)
 	} else {
+		if global.App.Config().Trace.Enable {
+			_ = db.Use(tracing.GormPlugin{})
+		}
 		sqlDB, _ := db.DB()
 		sqlDB.SetMaxIdleConns(dbConfig.MaxIdleConns)
 		sqlDB.SetMaxOpenConns(dbConfig.MaxOpenConns)
-		initMysqlTable(db)
-		return db
+		if err := initMysqlTable(db); err != nil {
+			_ = sqlDB.Close()
+			return nil, err
+		}
+		return db, nil
 	}
 }
 
-func initMysqlTable(db *gorm.DB) {
+func initMysqlTable(db *gorm.DB) error {
 	err := db.AutoMigrate(
 		models.User{},
 		models.Media{},
+		models.MediaVariant{},
 	)
 	if err != nil {
-		global.App.Log.Error("migrate table failed", zap.Any("err", err))
-		os.Exit(0)
+		global.App.ModuleLog(global.LogModuleDB).Error("migrate table failed", zap.Any("err", err))
 	}
+	return err
 }
 
 // 自定义 gorm writer
@@ -74,14 +85,14 @@ func getGormWriter() logger.Writer {
 	var writer io.Writer
 
 	// 是否启用日志文件
-	if global.App.Config.Database.EnableFileLogWriter {
+	if global.App.Config().Database.EnableFileLogWriter {
 		// 自定义 Writer，引入 lumberjack
 		writer = &lumberjack.Logger{
-			Filename:   global.App.Config.Log.RootDir + "/" + global.App.Config.Log.Filename,
-			MaxSize:    global.App.Config.Log.MaxSize,
-			MaxBackups: global.App.Config.Log.MaxBackups,
-			MaxAge:     global.App.Config.Log.MaxAge,
-			Compress:   global.App.Config.Log.Compress,
+			Filename:   global.App.Config().Log.RootDir + "/" + global.App.Config().Log.Filename,
+			MaxSize:    global.App.Config().Log.MaxSize,
+			MaxBackups: global.App.Config().Log.MaxBackups,
+			MaxAge:     global.App.Config().Log.MaxAge,
+			Compress:   global.App.Config().Log.Compress,
 		}
 	} else {
 		writer = os.Stdout
@@ -94,7 +105,7 @@ func getGormWriter() logger.Writer {
 func getGormLogger() logger.Interface {
 	var logMode logger.LogLevel
 
-	switch global.App.Config.Database.LogMode {
+	switch global.App.Config().Database.LogMode {
 	case "silent":
 		logMode = logger.Silent
 	case "error":
@@ -108,9 +119,9 @@ func getGormLogger() logger.Interface {
 	}
 
 	return logger.New(getGormWriter(), logger.Config{
-		SlowThreshold:             200 * time.Millisecond,                         // 慢 sql 阈值
-		LogLevel:                  logMode,                                        // 日志级别
-		IgnoreRecordNotFoundError: false,                                          // 忽略 ErrRecordNotFound 错误
-		Colorful:                  global.App.Config.Database.EnableFileLogWriter, // 禁用彩色打印
+		SlowThreshold:             200 * time.Millisecond,                           // 慢 sql 阈值
+		LogLevel:                  logMode,                                          // 日志级别
+		IgnoreRecordNotFoundError: false,                                            // 忽略 ErrRecordNotFound 错误
+		Colorful:                  global.App.Config().Database.EnableFileLogWriter, // 禁用彩色打印
 	})
 }
@@ -0,0 +1,37 @@
+package bootstrap
+
+import (
+	"my-gin/app/common/health"
+	"my-gin/global"
+	"time"
+)
+
+// 单项检查默认超时时间
+const defaultHealthTimeout = time.Second
+
+// InitializeHealth 注册就绪检查项，需在数据库、Redis、文件系统初始化后调用
+func InitializeHealth() *health.Registry {
+	healthConfig := global.App.Config().Health
+	registry := health.NewRegistry(time.Duration(healthConfig.CacheTTL) * time.Millisecond)
+
+	checkers := []health.Checker{
+		health.DBChecker{DB: global.App.DB},
+		health.RedisChecker{Client: global.App.Redis},
+		health.DiskChecker{RootDir: global.App.Config().Storage.Disks.Local.RootDir},
+	}
+	for _, checker := range checkers {
+		registry.Register(checker, healthTimeout(checker.Name()))
+	}
+	return registry
+}
+
+func healthTimeout(name string) time.Duration {
+	healthConfig := global.App.Config().Health
+	if timeout, ok := healthConfig.Timeouts[name]; ok && timeout > 0 {
+		return time.Duration(timeout) * time.Millisecond
+	}
+	if healthConfig.Timeout > 0 {
+		return time.Duration(healthConfig.Timeout) * time.Millisecond
+	}
+	return defaultHealthTimeout
+}
@@ -0,0 +1,159 @@
+package bootstrap
+
+import (
+	"context"
+	"my-gin/app/common/lifecycle"
+	"my-gin/app/service"
+	"my-gin/global"
+	"time"
+)
+
+const (
+	// 未单独配置时钩子的超时时间
+	defaultHookTimeout = 10 * time.Second
+	// 未配置 upload.cleanup_interval 时过期分片上传的清理间隔
+	defaultUploadCleanupInterval = 10 * time.Minute
+)
+
+// InitializeLifecycle 注册各组件的启动及关闭钩子，需在日志初始化后调用
+func InitializeLifecycle() *lifecycle.Lifecycle {
+	lc := lifecycle.New(global.App.ModuleLog("lifecycle"), defaultHookTimeout)
+
+	lc.Append(lifecycle.Hook{
+		Name: "alert",
+		OnStart: func(ctx context.Context) error {
+			global.App.Alert = InitializeAlert()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			return global.App.Alert.Wait(ctx)
+		},
+	})
+
+	// 链路追踪需先于数据库及 Redis 初始化，以便注册插件
+	lc.Append(lifecycle.Hook{
+		Name: "trace",
+		OnStart: func(ctx context.Context) error {
+			InitializeTrace()
+			return nil
+		},
+		OnStop: CloseTrace,
+	})
+
+	lc.Append(lifecycle.Hook{
+		Name:      "db",
+		DependsOn: []string{"trace"},
+		Timeout:   30 * time.Second,
+		OnStart: func(ctx context.Context) (err error) {
+			global.App.DB, err = InitializeDB()
+			return err
+		},
+		OnStop: func(ctx context.Context) error {
+			if global.App.DB == nil {
+				return nil
+			}
+			db, err := global.App.DB.DB()
+			if err != nil {
+				return err
+			}
+			return db.Close()
+		},
+	})
+
+	lc.Append(lifecycle.Hook{
+		Name: "validator",
+		OnStart: func(ctx context.Context) error {
+			InitializeValidator()
+			return nil
+		},
+	})
+
+	lc.Append(lifecycle.Hook{
+		Name:      "redis",
+		DependsOn: []string{"trace"},
+		OnStart: func(ctx context.Context) (err error) {
+			global.App.Redis, err = InitializeRedis()
+			return err
+		},
+		OnStop: func(ctx context.Context) error {
+			if global.App.Redis == nil {
+				return nil
+			}
+			return global.App.Redis.Close()
+		},
+	})
+
+	lc.Append(lifecycle.Hook{
+		Name: "storage",
+		OnStart: func(ctx context.Context) error {
+			return InitializeStorage()
+		},
+	})
+
+	// 定期清理过期未完成的分片上传
+	var uploadCleaner *service.UploadCleaner
+	lc.Append(lifecycle.Hook{
+		Name:      "upload_cleaner",
+		DependsOn: []string{"redis", "storage"},
+		OnStart: func(ctx context.Context) error {
+			uploadCleaner = service.NewUploadCleaner(global.App.Redis, global.App.Disk, global.App.ModuleLog(global.LogModuleStorage), uploadCleanupInterval())
+			uploadCleaner.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			if uploadCleaner == nil {
+				return nil
+			}
+			return uploadCleaner.Stop(ctx)
+		},
+	})
+
+	// 定期检查孤儿文件，多实例部署时由分布式锁保证仅一个实例执行
+	var orphanCleaner *service.OrphanCleaner
+	lc.Append(lifecycle.Hook{
+		Name:      "orphan_cleaner",
+		DependsOn: []string{"db", "redis", "storage"},
+		OnStart: func(ctx context.Context) error {
+			interval := global.App.Config().Storage.Gc.Interval
+			if interval <= 0 {
+				return nil
+			}
+			orphanCleaner = service.NewOrphanCleaner(global.App.ConfigStore, global.App.DB, global.App.Disk, global.NewLocker(global.App.Redis, NewLockObserver()), global.App.ModuleLog(global.LogModuleStorage))
+			orphanCleaner.Start(time.Duration(interval) * time.Second)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			if orphanCleaner == nil {
+				return nil
+			}
+			return orphanCleaner.Stop(ctx)
+		},
+	})
+
+	lc.Append(lifecycle.Hook{
+		Name:      "metrics",
+		DependsOn: []string{"db", "redis"},
+		OnStart: func(ctx context.Context) error {
+			InitializeMetrics()
+			return nil
+		},
+	})
+
+	lc.Append(lifecycle.Hook{
+		Name:      "health",
+		DependsOn: []string{"db", "redis", "storage"},
+		OnStart: func(ctx context.Context) error {
+			global.App.Health = InitializeHealth()
+			return nil
+		},
+	})
+
+	return lc
+}
+
+func uploadCleanupInterval() time.Duration {
+	if interval := global.App.Config().Upload.CleanupInterval; interval > 0 {
+		return time.Duration(interval) * time.Second
+	}
+	return defaultUploadCleanupInterval
+}
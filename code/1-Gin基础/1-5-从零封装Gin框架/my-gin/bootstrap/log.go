@@ -1,71 +1,103 @@
 package bootstrap
 
 import (
+	"errors"
 	"fmt"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"gopkg.in/natefinch/lumberjack.v2"
+	"my-gin/config"
 	"my-gin/global"
 	"my-gin/utils"
 	"os"
 	"time"
 )
 
-var (
-	level   zapcore.Level // zap 日志等级
-	options []zap.Option  // zap 配置项
-)
-
 func InitializeLog() *zap.Logger {
 	// 创建根目录
 	createRootDir()
 
+	// 初始化脱敏规则
+	redactor, err := utils.NewRedactor(global.App.Config().Log.Redact.Fields, global.App.Config().Log.Redact.Patterns)
+	if err != nil {
+		fmt.Println("invalid log redact pattern, error: ", err)
+		redactor, _ = utils.NewRedactor(nil, nil)
+	}
+	global.App.Redactor = redactor
+
 	// 设置日志等级
-	setLogLevel()
+	level := getLogLevel()
+	global.App.LogLevels = global.NewLogLevels(level)
+	if err := global.App.LogLevels.SetModules(global.App.Config().Log.Modules); err != nil {
+		fmt.Println("invalid log module level, error: ", err)
+	}
+	// 日志等级配置变更时热更新
+	global.App.ConfigStore.Subscribe(func(_, _ *config.Configuration) {
+		ReloadLogLevel()
+	}, "log.level", "log.modules")
 
-	if global.App.Config.Log.ShowLine {
+	var options []zap.Option // zap 配置项
+	if level == zap.DebugLevel || level == zap.ErrorLevel {
+		options = append(options, zap.AddStacktrace(level))
+	}
+	if global.App.Config().Log.ShowLine {
 		options = append(options, zap.AddCaller())
 	}
 
-	// 初始化 zap
-	return zap.New(getZapCore(), options...)
+	// 初始化 zap，core 本身不过滤等级，由 LogLevels 在运行时判断
+	logger := zap.New(getZapCore(), options...)
+	return global.WithLevel(logger, global.App.LogLevels.Root())
+}
+
+// ReloadLogLevel 按当前配置重载日志等级
+func ReloadLogLevel() {
+	if global.App.LogLevels == nil {
+		return
+	}
+	if err := global.App.LogLevels.Reset(global.App.Config().Log.Level, global.App.Config().Log.Modules); err != nil {
+		global.App.Log.Error("reload log level failed", zap.Any("err", err))
+		return
+	}
+	global.App.Log.Info("log level reloaded", zap.String("level", global.App.LogLevels.Root().String()))
 }
 
 func createRootDir() {
-	if ok, _ := utils.PathExists(global.App.Config.Log.RootDir); !ok {
-		err := os.MkdirAll(global.App.Config.Log.RootDir, os.ModePerm)
+	if ok, _ := utils.PathExists(global.App.Config().Log.RootDir); !ok {
+		err := os.MkdirAll(global.App.Config().Log.RootDir, os.ModePerm)
 		if err != nil {
 			fmt.Println("can't create directory in the path, error: ", err)
 		}
 	}
 }
 
-func setLogLevel() {
-	switch global.App.Config.Log.Level {
+func getLogLevel() zapcore.Level {
+	switch global.App.Config().Log.Level {
 	case "debug":
-		level = zap.DebugLevel
-		options = append(options, zap.AddStacktrace(level))
+		return zap.DebugLevel
 	case "info":
-		level = zap.InfoLevel
+		return zap.InfoLevel
 	case "warn":
-		level = zap.WarnLevel
+		return zap.WarnLevel
 	case "error":
-		level = zap.ErrorLevel
-		options = append(options, zap.AddStacktrace(level))
+		return zap.ErrorLevel
 	case "dpanic":
-		level = zap.DPanicLevel
+		return zap.DPanicLevel
 	case "panic":
-		level = zap.PanicLevel
+		return zap.PanicLevel
 	case "fatal":
-		level = zap.FatalLevel
+		return zap.FatalLevel
 	default:
-		level = zap.InfoLevel
+		return zap.InfoLevel
 	}
 }
 
+// 日志写入器的释放函数，程序退出时由 CloseLog 调用
+var logClosers []func() error
+
 // 扩展 zap
 func getZapCore() zapcore.Core {
-	var encoder zapcore.Encoder
+	// 重新初始化时先刷新并关闭之前的写入器
+	CloseLog()
 
 	// 调整编码器默认配置
 	encoderConfig := zap.NewProductionEncoderConfig()
@@ -73,29 +105,103 @@ func getZapCore() zapcore.Core {
 		encoder.AppendString(time.Format("[" + "2006-01-02 15:04:05.000" + "]"))
 	}
 	encoderConfig.EncodeLevel = func(l zapcore.Level, encoder zapcore.PrimitiveArrayEncoder) {
-		encoder.AppendString(global.App.Config.App.Env + "." + l.String())
+		encoder.AppendString(global.App.Config().App.Env + "." + l.String())
 	}
 
 	// 设置编码器
-	if global.App.Config.Log.Format == "json" {
+	var encoder zapcore.Encoder
+	if global.App.Config().Log.Format == "json" {
 		encoder = zapcore.NewJSONEncoder(encoderConfig)
 	} else {
 		encoder = zapcore.NewConsoleEncoder(encoderConfig)
 	}
 
 	// 设置同时写入文件，并且打印到控制台日志
-	var writes = []zapcore.WriteSyncer{getLogWriter(), zapcore.AddSync(os.Stdout)}
-	return zapcore.NewCore(encoder, zapcore.NewMultiWriteSyncer(writes...), level)
+	var writes = []zapcore.WriteSyncer{getLogWriter(global.App.Config().Log.Filename), zapcore.AddSync(os.Stdout)}
+	cores := []zapcore.Core{
+		zapcore.NewCore(encoder, bufferWriter(zapcore.NewMultiWriteSyncer(writes...)), zap.DebugLevel),
+	}
+
+	// error 及以上等级单独写入文件
+	if global.App.Config().Log.ErrorFilename != "" {
+		errorWriter := bufferWriter(getLogWriter(global.App.Config().Log.ErrorFilename))
+		cores = append(cores, zapcore.NewCore(encoder.Clone(), errorWriter, zap.ErrorLevel))
+	}
+
+	// 额外输出统一使用 json 格式，方便日志采集
+	for _, sink := range global.App.Config().Log.Sinks {
+		level, err := zapcore.ParseLevel(sink.Level)
+		if err != nil {
+			level = zap.InfoLevel
+		}
+		core, err := getSinkCore(sink, zapcore.NewJSONEncoder(encoderConfig), level)
+		if err != nil {
+			fmt.Println("can't create log sink, error: ", err)
+			continue
+		}
+		cores = append(cores, core)
+	}
+
+	// 各输出分别脱敏，保证 Tee 中的等级过滤生效
+	for i := range cores {
+		cores[i] = newRedactCore(cores[i], global.App.Redactor)
+	}
+	return zapcore.NewTee(cores...)
 }
 
 // 使用 lumberjack 作为日志写入器
-func getLogWriter() zapcore.WriteSyncer {
+func getLogWriter(filename string) zapcore.WriteSyncer {
 	file := &lumberjack.Logger{
-		Filename:   global.App.Config.Log.RootDir + "/" + global.App.Config.Log.Filename,
-		MaxSize:    global.App.Config.Log.MaxSize,
-		MaxBackups: global.App.Config.Log.MaxBackups,
-		MaxAge:     global.App.Config.Log.MaxAge,
-		Compress:   global.App.Config.Log.Compress,
+		Filename:   global.App.Config().Log.RootDir + "/" + filename,
+		MaxSize:    global.App.Config().Log.MaxSize,
+		MaxBackups: global.App.Config().Log.MaxBackups,
+		MaxAge:     global.App.Config().Log.MaxAge,
+		Compress:   global.App.Config().Log.Compress,
+	}
+	logClosers = append(logClosers, file.Close)
+
+	if global.App.Config().Log.DailyRotate {
+		return zapcore.AddSync(newDailyRotateWriter(file))
 	}
 	return zapcore.AddSync(file)
 }
+
+// 根据配置创建 syslog / tcp / udp 日志输出，syslog 按日志等级写入对应优先级
+func getSinkCore(sink config.LogSink, encoder zapcore.Encoder, level zapcore.Level) (zapcore.Core, error) {
+	switch sink.Type {
+	case "syslog":
+		return newSyslogCore(sink.Network, sink.Address, sink.Tag, encoder, level)
+	case "tcp", "udp":
+		writer := newNetWriter(sink.Type, sink.Address)
+		logClosers = append(logClosers, writer.Close)
+		return zapcore.NewCore(encoder, bufferWriter(zapcore.AddSync(writer)), level), nil
+	}
+	return nil, errors.New("unknown log sink type: " + sink.Type)
+}
+
+// 开启缓冲写入时，使用 BufferedWriteSyncer 包装写入器
+func bufferWriter(ws zapcore.WriteSyncer) zapcore.WriteSyncer {
+	async := global.App.Config().Log.Async
+	if !async.Enable {
+		return ws
+	}
+	buffered := &zapcore.BufferedWriteSyncer{
+		WS:            ws,
+		Size:          async.BufferSize * 1024,
+		FlushInterval: time.Duration(async.FlushInterval) * time.Second,
+	}
+	// 先停止缓冲并刷新，再关闭底层写入器
+	logClosers = append([]func() error{buffered.Stop}, logClosers...)
+	return buffered
+}
+
+// CloseLog 刷新缓冲区并关闭日志写入器
+func CloseLog() {
+	if global.App.Log != nil {
+		_ = global.App.Log.Sync()
+	}
+	for _, closer := range logClosers {
+		_ = closer()
+	}
+	logClosers = nil
+}
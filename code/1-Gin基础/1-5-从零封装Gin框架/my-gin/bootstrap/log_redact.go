@@ -0,0 +1,102 @@
+package bootstrap
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"my-gin/utils"
+	"sort"
+)
+
+// redactCore 写入前对日志内容及字段脱敏
+type redactCore struct {
+	zapcore.Core
+	redactor *utils.Redactor
+}
+
+func newRedactCore(core zapcore.Core, redactor *utils.Redactor) zapcore.Core {
+	if redactor == nil {
+		return core
+	}
+	return &redactCore{core, redactor}
+}
+
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{c.Core.With(c.redact(fields)), c.redactor}
+}
+
+func (c *redactCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *redactCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = c.redactor.String(entry.Message)
+	entry.Stack = c.redactor.String(entry.Stack)
+	return c.Core.Write(entry, c.redact(fields))
+}
+
+func (c *redactCore) redact(fields []zapcore.Field) []zapcore.Field {
+	result := make([]zapcore.Field, 0, len(fields))
+	for _, field := range fields {
+		switch {
+		case field.Type == zapcore.StringType:
+			field.String = c.redactor.Value(field.Key, field.String)
+		case field.Type == zapcore.ByteStringType:
+			if b, ok := field.Interface.([]byte); ok {
+				field = zap.String(field.Key, c.redactor.Value(field.Key, string(b)))
+			}
+		case c.redactor.IsSensitive(field.Key):
+			field = zap.String(field.Key, utils.RedactMask)
+		case field.Type == zapcore.ErrorType:
+			if err, ok := field.Interface.(error); ok && err != nil {
+				field = zap.String(field.Key, c.redactor.String(err.Error()))
+			}
+		case field.Type == zapcore.StringerType:
+			if s, ok := field.Interface.(fmt.Stringer); ok {
+				field = zap.String(field.Key, c.redactor.String(stringerValue(s)))
+			}
+		case field.Type == zapcore.ReflectType:
+			field.Interface = c.redactor.Any(field.Interface)
+		case field.Type == zapcore.ObjectMarshalerType || field.Type == zapcore.ArrayMarshalerType:
+			// 先编码为 map、slice 再递归脱敏
+			enc := zapcore.NewMapObjectEncoder()
+			field.AddTo(enc)
+			field = zap.Any(field.Key, c.redactor.Any(enc.Fields[field.Key]))
+		case field.Type == zapcore.InlineMarshalerType:
+			enc := zapcore.NewMapObjectEncoder()
+			field.AddTo(enc)
+			result = append(result, c.inline(enc.Fields)...)
+			continue
+		}
+		result = append(result, field)
+	}
+	return result
+}
+
+// 内联对象展开为多个字段后脱敏，按字段名排序保证输出稳定
+func (c *redactCore) inline(values map[string]interface{}) []zapcore.Field {
+	redacted, _ := c.redactor.Any(values).(map[string]interface{})
+	keys := make([]string, 0, len(redacted))
+	for key := range redacted {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	fields := make([]zapcore.Field, len(keys))
+	for i, key := range keys {
+		fields[i] = zap.Any(key, redacted[key])
+	}
+	return fields
+}
+
+// 获取 Stringer 的字符串形式，String() panic（如 nil 指针接收者）时与 zap 一致记录 panic 信息
+func stringerValue(s fmt.Stringer) (value string) {
+	defer func() {
+		if err := recover(); err != nil {
+			value = fmt.Sprintf("PANIC=%v", err)
+		}
+	}()
+	return s.String()
+}
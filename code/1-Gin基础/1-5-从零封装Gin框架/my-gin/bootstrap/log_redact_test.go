@@ -0,0 +1,88 @@
+package bootstrap
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"my-gin/utils"
+	"strings"
+	"testing"
+)
+
+type redactUser struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+	Mobile   string `json:"mobile"`
+}
+
+type redactStringer struct{ token string }
+
+func (s redactStringer) String() string {
+	return "Bearer " + s.token
+}
+
+type redactObject struct{ token string }
+
+func (o redactObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("token", o.token)
+	enc.AddString("note", "call 13812345678")
+	return nil
+}
+
+type redactArray []string
+
+func (a redactArray) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, item := range a {
+		enc.AppendString(item)
+	}
+	return nil
+}
+
+func TestRedactCoreFieldTypes(t *testing.T) {
+	redactor, err := utils.NewRedactor(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		field zapcore.Field
+	}{
+		{"struct", zap.Any("user", redactUser{Name: "tom", Password: "secret-pw", Mobile: "13812345678"})},
+		{"struct pointer", zap.Any("user", &redactUser{Name: "tom", Password: "secret-pw", Mobile: "13812345678"})},
+		{"typed map", zap.Any("form", map[string]string{"password": "secret-pw", "mobile": "13812345678"})},
+		{"stringer", zap.Stringer("auth", redactStringer{"secret-pw"})},
+		{"byte string", zap.ByteString("body", []byte(`{"password":"x","note":"13812345678"}`))},
+		{"sensitive byte string", zap.ByteString("password", []byte("secret-pw"))},
+		{"object", zap.Object("obj", redactObject{"secret-pw"})},
+		{"array", zap.Array("list", redactArray{"13812345678", "Bearer secret-pw"})},
+		{"inline", zap.Inline(redactObject{"secret-pw"})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zap.DebugLevel)
+			zap.New(newRedactCore(core, redactor)).Info("test", tt.field)
+
+			entries := logs.All()
+			if len(entries) != 1 {
+				t.Fatalf("got %d entries, want 1", len(entries))
+			}
+			output := fmtFields(entries[0].ContextMap())
+			for _, leaked := range []string{"secret-pw", "13812345678"} {
+				if strings.Contains(output, leaked) {
+					t.Errorf("field %s leaks %q: %s", tt.name, leaked, output)
+				}
+			}
+		})
+	}
+}
+
+// 将字段编码为 json，便于检查是否包含敏感内容
+func fmtFields(fields map[string]interface{}) string {
+	enc := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	buf, err := enc.EncodeEntry(zapcore.Entry{}, []zapcore.Field{zap.Any("fields", fields)})
+	if err != nil {
+		return err.Error()
+	}
+	return buf.String()
+}
@@ -0,0 +1,12 @@
+//go:build windows || plan9
+
+package bootstrap
+
+import (
+	"errors"
+	"go.uber.org/zap/zapcore"
+)
+
+func newSyslogCore(network, address, tag string, encoder zapcore.Encoder, enabler zapcore.LevelEnabler) (zapcore.Core, error) {
+	return nil, errors.New("syslog is not supported on this platform")
+}
@@ -0,0 +1,52 @@
+//go:build !windows && !plan9
+
+package bootstrap
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslogCorePriority(t *testing.T) {
+	address := filepath.Join(t.TempDir(), "syslog.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: address, Net: "unixgram"})
+	if err != nil {
+		t.Skip("unixgram is not available: ", err)
+	}
+	defer conn.Close()
+
+	core, err := newSyslogCore("unixgram", address, "my-gin", zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zap.DebugLevel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer CloseLog()
+	logger := zap.New(core)
+
+	// LOG_LOCAL0 为 16，优先级 = 16*8 + severity
+	tests := []struct {
+		log  func(string, ...zap.Field)
+		want string
+	}{
+		{logger.Debug, "<135>"},
+		{logger.Info, "<134>"},
+		{logger.Warn, "<132>"},
+		{logger.Error, "<131>"},
+	}
+	buf := make([]byte, 4096)
+	for _, tt := range tests {
+		tt.log("syslog test")
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		n, err := conn.Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(buf[:n]); !strings.HasPrefix(got, tt.want) {
+			t.Errorf("got %q, want priority %s", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package bootstrap
+
+import (
+	"gopkg.in/natefinch/lumberjack.v2"
+	"net"
+	"sync"
+	"time"
+)
+
+// dailyRotateWriter 在 lumberjack 按大小切割的基础上，增加按天切割
+type dailyRotateWriter struct {
+	*lumberjack.Logger
+	mu  sync.Mutex
+	day string
+}
+
+func newDailyRotateWriter(logger *lumberjack.Logger) *dailyRotateWriter {
+	return &dailyRotateWriter{Logger: logger, day: time.Now().Format(time.DateOnly)}
+}
+
+func (w *dailyRotateWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	if today := time.Now().Format(time.DateOnly); today != w.day {
+		w.day = today
+		_ = w.Logger.Rotate()
+	}
+	w.mu.Unlock()
+	return w.Logger.Write(p)
+}
+
+// 网络连接超时时间
+const netSinkDialTimeout = 3 * time.Second
+
+// netWriter 将日志写入 TCP / UDP 连接，连接断开后在下一次写入时重连
+type netWriter struct {
+	network string
+	address string
+	mu      sync.Mutex
+	conn    net.Conn
+}
+
+func newNetWriter(network, address string) *netWriter {
+	return &netWriter{network: network, address: address}
+}
+
+func (w *netWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		conn, err := net.DialTimeout(w.network, w.address, netSinkDialTimeout)
+		if err != nil {
+			return 0, err
+		}
+		w.conn = conn
+	}
+
+	n, err := w.conn.Write(p)
+	if err != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+	return n, err
+}
+
+func (w *netWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
@@ -0,0 +1,26 @@
+package bootstrap
+
+import (
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"my-gin/app/common/metrics"
+	"my-gin/global"
+)
+
+// InitializeMetrics 注册数据库、Redis 连接池指标，需在数据库及 Redis 初始化后调用
+func InitializeMetrics() {
+	if global.App.DB != nil {
+		if sqlDB, err := global.App.DB.DB(); err == nil {
+			metrics.Registry.MustRegister(collectors.NewDBStatsCollector(sqlDB, global.App.Config().Database.Database))
+		}
+	}
+	if global.App.Redis != nil {
+		metrics.Registry.MustRegister(metrics.NewRedisPoolCollector(global.App.Redis))
+	}
+}
+
+// NewLockObserver 统计分布式锁操作结果
+func NewLockObserver() global.LockObserver {
+	return func(operation string, result string) {
+		metrics.LockOperationsTotal.WithLabelValues(operation, result).Inc()
+	}
+}
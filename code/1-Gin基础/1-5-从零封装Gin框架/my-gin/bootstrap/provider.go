@@ -0,0 +1,77 @@
+package bootstrap
+
+import (
+	"github.com/go-redis/redis/v8"
+	"github.com/google/wire"
+	"github.com/jassue/go-storage/storage"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"my-gin/app/common/alert"
+	"my-gin/app/common/health"
+	"my-gin/app/common/storage/signed"
+	"my-gin/app/controller/common"
+	"my-gin/app/middleware"
+	"my-gin/app/service"
+	"my-gin/config"
+	"my-gin/global"
+	"my-gin/routes"
+	"my-gin/utils"
+)
+
+// Http 服务依赖的路由、控制器及中间件，由 wire 生成的注入器创建
+type Http struct {
+	Conf       *config.Store
+	Middleware *middleware.Global
+	Api        *routes.ApiRoutes
+	Admin      *routes.AdminRoutes
+	Health     *common.HealthController
+}
+
+// Components 由生命周期启动的基础组件，作为注入器参数传入
+type Components struct {
+	Config    *config.Store
+	Log       *zap.Logger
+	LogLevels *global.LogLevels
+	Redactor  *utils.Redactor
+	Alert     *alert.Dispatcher
+	Health    *health.Registry
+	UrlSigner *signed.Signer
+	DB        *gorm.DB
+	Redis     *redis.Client
+}
+
+// StartedComponents 收集 Lifecycle.Start 后已初始化的基础组件
+func StartedComponents() *Components {
+	return &Components{
+		Config:    global.App.ConfigStore,
+		Log:       global.App.Log,
+		LogLevels: global.App.LogLevels,
+		Redactor:  global.App.Redactor,
+		Alert:     global.App.Alert,
+		Health:    global.App.Health,
+		UrlSigner: global.App.UrlSigner,
+		DB:        global.App.DB,
+		Redis:     global.App.Redis,
+	}
+}
+
+// ProviderSet 基础组件 provider
+var ProviderSet = wire.NewSet(
+	wire.FieldsOf(new(*Components), "Config", "Log", "LogLevels", "Redactor", "Alert", "Health", "UrlSigner", "DB", "Redis"),
+	global.NewModuleLogger,
+	global.NewLocker,
+	NewLockObserver,
+	NewDisks,
+	wire.Struct(new(Http), "*"),
+)
+
+// NewDisks 按名称获取文件系统，未传参时使用当前配置的默认驱动
+func NewDisks(conf *config.Store) service.Disks {
+	return func(disk ...string) (storage.Storage, error) {
+		diskName := conf.Load().Storage.Default
+		if len(disk) > 0 {
+			diskName = storage.DiskName(disk[0])
+		}
+		return storage.Disk(diskName)
+	}
+}
@@ -5,20 +5,27 @@ import (
 	"fmt"
 	"github.com/go-redis/redis/v8"
 	"go.uber.org/zap"
+	"my-gin/app/common/tracing"
 	"my-gin/global"
 )
 
-func InitializeRedis() *redis.Client {
+// InitializeRedis 连接失败时关闭客户端并返回错误
+func InitializeRedis() (*redis.Client, error) {
 	client := redis.NewClient(&redis.Options{
-		Addr:     fmt.Sprintf("%s:%d", global.App.Config.Redis.Host, global.App.Config.Redis.Port),
-		Password: global.App.Config.Redis.Password,
-		DB:       global.App.Config.Redis.DB,
+		Addr:     fmt.Sprintf("%s:%d", global.App.Config().Redis.Host, global.App.Config().Redis.Port),
+		Password: global.App.Config().Redis.Password,
+		DB:       global.App.Config().Redis.DB,
 	})
 
+	if global.App.Config().Trace.Enable {
+		client.AddHook(tracing.RedisHook{})
+	}
+
 	_, err := client.Ping(context.Background()).Result()
 	if err != nil {
 		global.App.Log.Error("Redis connect ping failed, err: ", zap.Any("err", err))
-		return nil
+		_ = client.Close()
+		return nil, fmt.Errorf("redis ping: %w", err)
 	}
-	return client
+	return client, nil
 }
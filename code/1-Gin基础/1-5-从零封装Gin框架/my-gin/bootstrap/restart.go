@@ -0,0 +1,265 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"my-gin/app/common/health"
+	"my-gin/global"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// 子进程继承的监听 fd 数量，fd 从 3 开始依次排列
+	envListenFds = "MY_GIN_LISTEN_FDS"
+	// 子进程就绪后写入通知的管道 fd
+	envReadyFd = "MY_GIN_READY_FD"
+
+	// 未配置时等待新进程就绪的超时时间
+	defaultRestartTimeout = 30 * time.Second
+)
+
+// 从父进程继承监听，非平滑重启启动时返回 nil
+func inheritedListeners() ([]net.Listener, error) {
+	value := os.Getenv(envListenFds)
+	if value == "" {
+		return nil, nil
+	}
+	_ = os.Unsetenv(envListenFds)
+
+	count, err := strconv.Atoi(value)
+	if err != nil || count <= 0 {
+		return nil, fmt.Errorf("invalid %s: %q", envListenFds, value)
+	}
+
+	listeners := make([]net.Listener, 0, count)
+	for i := 0; i < count; i++ {
+		file := os.NewFile(uintptr(3+i), "listener")
+		ln, err := net.FileListener(file)
+		_ = file.Close()
+		if err != nil {
+			return nil, err
+		}
+		// 由最后退出的进程删除 unix socket 文件
+		if unixLn, ok := ln.(*net.UnixListener); ok {
+			unixLn.SetUnlinkOnClose(true)
+		}
+		listeners = append(listeners, ln)
+	}
+	return listeners, nil
+}
+
+// 通知父进程已就绪，非平滑重启启动时忽略
+func notifyReady() {
+	value := os.Getenv(envReadyFd)
+	if value == "" {
+		return
+	}
+	_ = os.Unsetenv(envReadyFd)
+
+	fd, err := strconv.Atoi(value)
+	if err != nil {
+		return
+	}
+	pipe := os.NewFile(uintptr(fd), "ready")
+	_, _ = pipe.Write([]byte{1})
+	_ = pipe.Close()
+}
+
+// 等待服务就绪，超时前就绪检查一直未通过时返回 false
+func waitReady(timeout time.Duration) bool {
+	if global.App.Health == nil {
+		return true
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		ctx, cancel := context.WithDeadline(context.Background(), deadline)
+		ok := global.App.Health.Ready(ctx).Status == health.StatusOk
+		cancel()
+		if ok {
+			return true
+		}
+		if time.Now().Add(time.Second).After(deadline) {
+			return false
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// 启动新的二进制并移交监听，新进程就绪后返回，失败时终止新进程
+func restart(listeners []net.Listener) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	files := make([]*os.File, 0, len(listeners)+1)
+	defer func() {
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}()
+	for _, ln := range listeners {
+		f, ok := ln.(interface{ File() (*os.File, error) })
+		if !ok {
+			return fmt.Errorf("listener %s does not support handoff", ln.Addr())
+		}
+		file, err := f.File()
+		if err != nil {
+			return err
+		}
+		files = append(files, file)
+	}
+
+	readR, readW, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer readR.Close()
+	files = append(files, readW)
+
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = files
+	cmd.Env = append(restartEnv(),
+		envListenFds+"="+strconv.Itoa(len(listeners)),
+		envReadyFd+"="+strconv.Itoa(3+len(listeners)),
+	)
+	err = cmd.Start()
+	for _, ln := range listeners {
+		_ = setNonblock(ln)
+	}
+	if err != nil {
+		return err
+	}
+	// 父进程不再持有写端，子进程异常退出时读取立即返回 EOF
+	_ = readW.Close()
+	files = files[:len(files)-1]
+
+	ready := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 1)
+		if _, err := readR.Read(buf); err != nil {
+			ready <- errors.New("new process exited before ready")
+			return
+		}
+		ready <- nil
+	}()
+
+	select {
+	case err = <-ready:
+	case <-time.After(restartTimeout()):
+		err = errors.New("timeout waiting for new process to be ready")
+	}
+	if err != nil {
+		_ = cmd.Process.Kill()
+		go func() { _ = cmd.Wait() }()
+		return err
+	}
+
+	// 新进程接管后由其自行管理生命周期
+	_ = cmd.Process.Release()
+	return nil
+}
+
+// 关闭已移交的监听，父进程不再接收新连接，由新进程继续 accept
+func closeListeners(listeners []net.Listener) {
+	for _, ln := range listeners {
+		// unix socket 文件仍由新进程使用，不能删除
+		if unixLn, ok := ln.(*net.UnixListener); ok {
+			unixLn.SetUnlinkOnClose(false)
+		}
+		_ = ln.Close()
+	}
+}
+
+// 记录已接收但尚未读取到请求的连接
+// http.Server 开始关闭后会直接断开这类连接，移交监听后需等待其进入处理状态再关闭
+type pendingConns struct {
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+}
+
+func newPendingConns() *pendingConns {
+	return &pendingConns{conns: make(map[net.Conn]struct{})}
+}
+
+// ConnState 用作 http.Server 的 ConnState 回调
+func (p *pendingConns) ConnState(conn net.Conn, state http.ConnState) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if state == http.StateNew {
+		p.conns[conn] = struct{}{}
+	} else {
+		delete(p.conns, conn)
+	}
+}
+
+// 等待所有新连接读取到请求或关闭
+func (p *pendingConns) wait(timeout time.Duration) {
+	// 先等待一个周期，覆盖 Accept 刚返回、还未回调 StateNew 的连接
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		p.mu.Lock()
+		n := len(p.conns)
+		p.mu.Unlock()
+		if n == 0 {
+			return
+		}
+	}
+}
+
+// 去掉上次重启遗留的环境变量
+func restartEnv() []string {
+	env := make([]string, 0, len(os.Environ()))
+	for _, kv := range os.Environ() {
+		if strings.HasPrefix(kv, envListenFds+"=") || strings.HasPrefix(kv, envReadyFd+"=") {
+			continue
+		}
+		env = append(env, kv)
+	}
+	return env
+}
+
+func restartTimeout() time.Duration {
+	if timeout := global.App.Config().Server.RestartTimeout; timeout > 0 {
+		return time.Duration(timeout) * time.Second
+	}
+	return defaultRestartTimeout
+}
+
+// 写入 pid 文件
+func writePidFile() error {
+	pidFile := global.App.Config().Server.PidFile
+	if pidFile == "" {
+		return nil
+	}
+	tmp := pidFile + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strconv.Itoa(os.Getpid())), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, pidFile)
+}
+
+// 删除 pid 文件，已被新进程覆盖时保留
+func removePidFile() {
+	pidFile := global.App.Config().Server.PidFile
+	if pidFile == "" {
+		return
+	}
+	content, err := os.ReadFile(pidFile)
+	if err != nil || strings.TrimSpace(string(content)) != strconv.Itoa(os.Getpid()) {
+		return
+	}
+	_ = os.Remove(pidFile)
+}
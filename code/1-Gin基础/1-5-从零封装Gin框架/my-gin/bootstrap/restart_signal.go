@@ -0,0 +1,33 @@
+//go:build !windows && !plan9
+
+package bootstrap
+
+import (
+	"net"
+	"os"
+	"syscall"
+)
+
+// 触发平滑重启的信号
+var restartSignals = []os.Signal{syscall.SIGHUP, syscall.SIGUSR2}
+
+// 恢复监听的非阻塞模式
+// exec 传递 ExtraFiles 时会将 fd 置为阻塞模式，dup 出的 fd 与监听共享该状态，
+// 不恢复时 accept 会阻塞在系统调用中，关闭监听需等到下一个连接到来
+func setNonblock(ln net.Listener) error {
+	conn, ok := ln.(syscall.Conn)
+	if !ok {
+		return nil
+	}
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var setErr error
+	if err = raw.Control(func(fd uintptr) {
+		setErr = syscall.SetNonblock(int(fd), true)
+	}); err != nil {
+		return err
+	}
+	return setErr
+}
@@ -0,0 +1,15 @@
+//go:build windows || plan9
+
+package bootstrap
+
+import (
+	"net"
+	"os"
+)
+
+// 当前平台不支持移交监听 fd，不注册平滑重启信号
+var restartSignals []os.Signal
+
+func setNonblock(ln net.Listener) error {
+	return nil
+}
@@ -0,0 +1,153 @@
+//go:build !windows && !plan9
+
+package bootstrap
+
+import (
+	"fmt"
+	"io"
+	"my-gin/config"
+	"my-gin/global"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// 测试二进制被 restart 重新执行时，按该环境变量以子进程身份运行
+const envRestartTestChild = "MY_GIN_RESTART_TEST_CHILD"
+
+func TestMain(m *testing.M) {
+	switch os.Getenv(envRestartTestChild) {
+	case "":
+		os.Exit(m.Run())
+	case "ready":
+		// 在继承的监听上提供服务后通知父进程
+		listeners, err := inheritedListeners()
+		if err != nil || len(listeners) != 1 {
+			os.Exit(2)
+		}
+		go func() { _ = http.Serve(listeners[0], replyHandler("child")) }()
+		notifyReady()
+	case "hang":
+		// 不提供服务也不通知就绪，由父进程超时后终止
+	case "exit":
+		// 就绪前异常退出
+		os.Exit(1)
+	}
+	time.Sleep(time.Minute)
+	os.Exit(0)
+}
+
+// 响应进程标识及 pid，便于测试结束后终止子进程
+func replyHandler(name string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, "%s %d", name, os.Getpid())
+	})
+}
+
+func setRestartTimeout(t *testing.T, seconds int) {
+	t.Helper()
+	conf := &config.Configuration{}
+	conf.Server.RestartTimeout = seconds
+	old := global.App.ConfigStore.Swap(conf)
+	t.Cleanup(func() { global.App.ConfigStore.Swap(old) })
+}
+
+// 启动父进程服务，测试结束后关闭
+func serveParent(t *testing.T) net.Listener {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &http.Server{Handler: replyHandler("parent")}
+	go func() { _ = server.Serve(ln) }()
+	t.Cleanup(func() { _ = server.Close() })
+	return ln
+}
+
+// 每次使用新连接请求，返回进程标识及 pid
+func get(t *testing.T, addr string) (string, int) {
+	t.Helper()
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}, Timeout: 5 * time.Second}
+	resp, err := client.Get("http://" + addr)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	name, pid, _ := strings.Cut(string(body), " ")
+	n, _ := strconv.Atoi(pid)
+	return name, n
+}
+
+func TestRestartHandoff(t *testing.T) {
+	setRestartTimeout(t, 10)
+	t.Setenv(envRestartTestChild, "ready")
+	ln := serveParent(t)
+	addr := ln.Addr().String()
+
+	if err := restart([]net.Listener{ln}); err != nil {
+		t.Fatalf("restart() error = %v", err)
+	}
+
+	// 父进程仍在 accept 时，子进程已在继承的监听上提供服务
+	childPid := 0
+	for i := 0; i < 500 && childPid == 0; i++ {
+		if name, pid := get(t, addr); name == "child" {
+			childPid = pid
+		}
+	}
+	if childPid == 0 {
+		t.Fatal("child did not serve on the inherited listener while the parent was accepting")
+	}
+	t.Cleanup(func() { _ = syscall.Kill(childPid, syscall.SIGKILL) })
+
+	// 父进程关闭监听后，所有请求由子进程处理
+	closeListeners([]net.Listener{ln})
+	for i := 0; i < 20; i++ {
+		if name, _ := get(t, addr); name != "child" {
+			t.Fatalf("request %d served by %s after the parent closed its listener", i, name)
+		}
+	}
+}
+
+func TestRestartChildNotReady(t *testing.T) {
+	setRestartTimeout(t, 1)
+	t.Setenv(envRestartTestChild, "hang")
+	ln := serveParent(t)
+
+	start := time.Now()
+	err := restart([]net.Listener{ln})
+	if err == nil {
+		t.Fatal("restart() should fail when the child never signals ready")
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("restart() returned after %v, want to wait for the restart timeout", elapsed)
+	}
+
+	// 子进程已被终止，父进程继续提供服务
+	for i := 0; i < 20; i++ {
+		if name, _ := get(t, ln.Addr().String()); name != "parent" {
+			t.Fatalf("request %d served by %s, want parent", i, name)
+		}
+	}
+}
+
+func TestRestartChildExited(t *testing.T) {
+	setRestartTimeout(t, 10)
+	t.Setenv(envRestartTestChild, "exit")
+	ln := serveParent(t)
+
+	err := restart([]net.Listener{ln})
+	if err == nil || !strings.Contains(err.Error(), "exited") {
+		t.Fatalf("restart() error = %v, want child exited error", err)
+	}
+	if name, _ := get(t, ln.Addr().String()); name != "parent" {
+		t.Fatalf("request served by %s, want parent", name)
+	}
+}
@@ -2,11 +2,14 @@ package bootstrap
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"log"
+	"my-gin/app/common/metrics"
 	"my-gin/app/middleware"
 	"my-gin/global"
-	"my-gin/routes"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -14,12 +17,17 @@ import (
 	"time"
 )
 
-func setupRouter() *gin.Engine {
-	if global.App.Config.App.Env == "production" {
+func setupRouter(h *Http) *gin.Engine {
+	if h.Conf.Load().App.Env == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
+	m := h.Middleware
 	router := gin.New()
-	router.Use(gin.Logger(), middleware.CustomRecovery(), middleware.Cors())
+	router.Use(m.RequestId.Handle())
+	if h.Conf.Load().Trace.Enable {
+		router.Use(middleware.Trace())
+	}
+	router.Use(m.AccessLog.Handle(), middleware.Metrics(), m.Recovery.Handle(), m.ErrorHandler.Handle(), m.Cors.Handle())
 
 	// 前端项目静态资源
 	router.StaticFile("/", "./static/dist/index.html")
@@ -30,38 +38,146 @@ func setupRouter() *gin.Engine {
 	router.Static("/public", "./static")
 	router.Static("/storage", "./storage/app/public")
 
+	// 存活及就绪检查
+	router.GET("/healthz", h.Health.Healthz)
+	router.GET("/readyz", h.Health.Readyz)
+
 	// 注册 api 分组路由
 	apiGroup := router.Group("/api")
-	routes.SetApiGroupRoutes(apiGroup)
+	h.Api.SetApiGroupRoutes(apiGroup)
+
+	// 未配置单独的管理端口时，管理接口与业务接口共用端口
+	if h.Conf.Load().Admin.Port == "" {
+		setupAdminRoutes(router, h, true)
+	}
 
 	return router
 }
 
-// RunServer 启动服务器
-func RunServer() {
-	r := setupRouter()
+// 管理端口路由
+func setupAdminRouter(h *Http) *gin.Engine {
+	router := gin.New()
+	m := h.Middleware
+	router.Use(m.RequestId.Handle(), m.Recovery.Handle(), m.ErrorHandler.Handle())
+	setupAdminRoutes(router, h, false)
+	return router
+}
 
-	srv := &http.Server{
-		Addr:    ":" + global.App.Config.App.Port,
-		Handler: r,
+// 注册指标及 admin 分组路由
+// 与业务接口共用端口时，指标接口同样需要 X-Admin-Token，单独的管理端口默认只监听本机（admin.host），无需鉴权
+func setupAdminRoutes(router *gin.Engine, h *Http, shared bool) {
+	if h.Conf.Load().Metrics.Enable {
+		path := h.Conf.Load().Metrics.Path
+		if path == "" {
+			path = "/metrics"
+		}
+		handlers := []gin.HandlerFunc{gin.WrapH(metrics.Handler())}
+		if shared {
+			handlers = append([]gin.HandlerFunc{middleware.AdminAuth(h.Conf)}, handlers...)
+		}
+		router.GET(path, handlers...)
 	}
 
+	adminGroup := router.Group("/admin")
+	h.Admin.SetAdminGroupRoutes(adminGroup)
+}
+
+// RunServer 启动服务器，收到退出信号并完成优雅关闭后返回
+func RunServer(h *Http) error {
+	r := setupRouter(h)
+
+	srv, err := newHttpServer(":"+global.App.Config().App.Port, r)
+	if err != nil {
+		return fmt.Errorf("server config: %w", err)
+	}
+
+	// 平滑重启启动的新进程直接使用父进程移交的监听
+	listeners, err := inheritedListeners()
+	if err != nil {
+		return fmt.Errorf("inherit listeners: %w", err)
+	}
+	if listeners == nil {
+		if listeners, err = newListeners(); err != nil {
+			return fmt.Errorf("listen: %w", err)
+		}
+	}
+
+	serveErr := make(chan error, 2)
+	pending := newPendingConns()
+	srv.ConnState = pending.ConnState
 	go func() {
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("listen: %s\n", err)
+		if err := serve(srv, listeners[0]); err != nil && err != http.ErrServerClosed && !errors.Is(err, net.ErrClosed) {
+			serveErr <- fmt.Errorf("listen: %w", err)
 		}
 	}()
 
-	// 等待中断信号以优雅地关闭服务器，设置 5 s的超时时间
-	quit := make(chan os.Signal)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	// 管理接口单独监听端口
+	var adminSrv *http.Server
+	if len(listeners) > 1 {
+		adminSrv = &http.Server{
+			Handler:           setupAdminRouter(h),
+			ReadHeaderTimeout: srv.ReadHeaderTimeout,
+		}
+		go func() {
+			if err := adminSrv.Serve(listeners[1]); err != nil && err != http.ErrServerClosed && !errors.Is(err, net.ErrClosed) {
+				serveErr <- fmt.Errorf("admin listen: %w", err)
+			}
+		}()
+	}
+
+	if os.Getenv(envReadyFd) != "" && !waitReady(restartTimeout()) {
+		closeListeners(listeners)
+		return errors.New("new process not ready, exiting")
+	}
+	notifyReady()
+	if err := writePidFile(); err != nil {
+		log.Printf("write pid file: %s\n", err)
+	}
+	defer removePidFile()
+
+	// 等待中断信号以优雅地关闭服务器，收到重启信号时先移交监听给新进程
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, append([]os.Signal{syscall.SIGINT, syscall.SIGTERM}, restartSignals...)...)
+	defer signal.Stop(quit)
+	restarted := false
+	var runErr error
+wait:
+	for {
+		select {
+		case runErr = <-serveErr:
+			break wait
+		case sig := <-quit:
+			if sig == syscall.SIGINT || sig == syscall.SIGTERM {
+				break wait
+			}
+			log.Println("Restart Server ...")
+			if err := restart(listeners); err != nil {
+				log.Printf("restart: %s\n", err)
+				continue
+			}
+			closeListeners(listeners)
+			pending.wait(shutdownTimeout())
+			restarted = true
+			break wait
+		}
+	}
 	log.Println("Shutdown Server ...")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	// 先让就绪检查失败，等待负载均衡摘除流量后再关闭；平滑重启时新进程已在同一监听上接收请求，无需等待
+	if !restarted && runErr == nil {
+		global.App.Health.SetShuttingDown()
+		time.Sleep(time.Duration(global.App.Config().Health.ShutdownDelay) * time.Second)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout())
 	defer cancel()
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatal("Server Shutdown:", err)
+	if adminSrv != nil {
+		_ = adminSrv.Shutdown(ctx)
+	}
+	// 平滑重启时监听已提前关闭，忽略重复关闭的错误
+	if err := srv.Shutdown(ctx); err != nil && !errors.Is(err, net.ErrClosed) && runErr == nil {
+		runErr = fmt.Errorf("server shutdown: %w", err)
 	}
 	log.Println("Server exiting")
+	return runErr
 }
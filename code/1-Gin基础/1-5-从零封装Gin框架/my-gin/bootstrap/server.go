@@ -0,0 +1,141 @@
+package bootstrap
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"my-gin/global"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+// 未配置时的优雅关闭超时时间
+const defaultShutdownTimeout = 5 * time.Second
+
+// 未配置 admin.host 时管理端口只监听本机
+const defaultAdminHost = "127.0.0.1"
+
+// 按 server 配置创建 http.Server
+func newHttpServer(addr string, handler http.Handler) (*http.Server, error) {
+	serverConfig := global.App.Config().Server
+
+	if serverConfig.H2C && !serverConfig.Tls.Enable {
+		handler = h2c.NewHandler(handler, &http2.Server{})
+	}
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadTimeout:       time.Duration(serverConfig.ReadTimeout) * time.Second,
+		ReadHeaderTimeout: time.Duration(serverConfig.ReadHeaderTimeout) * time.Second,
+		WriteTimeout:      time.Duration(serverConfig.WriteTimeout) * time.Second,
+		IdleTimeout:       time.Duration(serverConfig.IdleTimeout) * time.Second,
+		MaxHeaderBytes:    serverConfig.MaxHeaderBytes,
+	}
+
+	if serverConfig.Tls.Enable {
+		tlsConfig, err := getTlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		srv.TLSConfig = tlsConfig
+	}
+	return srv, nil
+}
+
+// 加载服务端证书，配置客户端 CA 时启用 mTLS
+func getTlsConfig() (*tls.Config, error) {
+	tlsConfig := global.App.Config().Server.Tls
+	cert, err := tls.LoadX509KeyPair(tlsConfig.CertFile, tlsConfig.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if tlsConfig.ClientCaFile == "" {
+		return config, nil
+	}
+
+	caPem, err := os.ReadFile(tlsConfig.ClientCaFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPem) {
+		return nil, errors.New("no certificate found in " + tlsConfig.ClientCaFile)
+	}
+	config.ClientCAs = pool
+
+	switch tlsConfig.ClientAuth {
+	case "request":
+		config.ClientAuth = tls.RequestClientCert
+	case "verify_if_given":
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	default:
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
+
+// 创建监听，配置 unix socket 时优先使用
+func newListener() (net.Listener, error) {
+	socket := global.App.Config().Server.UnixSocket
+	if socket == "" {
+		return net.Listen("tcp", ":"+global.App.Config().App.Port)
+	}
+
+	// 清理上次未正常退出遗留的 socket 文件
+	if err := os.Remove(socket); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return net.Listen("unix", socket)
+}
+
+// 创建业务监听及管理端口监听，顺序与平滑重启移交的 fd 一致
+func newListeners() ([]net.Listener, error) {
+	ln, err := newListener()
+	if err != nil {
+		return nil, err
+	}
+	listeners := []net.Listener{ln}
+
+	if port := global.App.Config().Admin.Port; port != "" {
+		adminLn, err := net.Listen("tcp", net.JoinHostPort(adminHost(), port))
+		if err != nil {
+			_ = ln.Close()
+			return nil, err
+		}
+		listeners = append(listeners, adminLn)
+	}
+	return listeners, nil
+}
+
+// 启动服务，启用 TLS 时使用 HTTPS（自动支持 HTTP/2）
+func serve(srv *http.Server, ln net.Listener) error {
+	if srv.TLSConfig != nil {
+		return srv.ServeTLS(ln, "", "")
+	}
+	return srv.Serve(ln)
+}
+
+// 管理端口监听地址，未配置时只监听本机
+func adminHost() string {
+	if host := global.App.Config().Admin.Host; host != "" {
+		return host
+	}
+	return defaultAdminHost
+}
+
+func shutdownTimeout() time.Duration {
+	if timeout := global.App.Config().Server.ShutdownTimeout; timeout > 0 {
+		return time.Duration(timeout) * time.Second
+	}
+	return defaultShutdownTimeout
+}
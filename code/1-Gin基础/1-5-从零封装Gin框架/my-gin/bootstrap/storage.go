@@ -1,10 +1,73 @@
 package bootstrap
 
 import (
-	"github.com/jassue/go-storage/local"
+	"fmt"
+	"github.com/jassue/go-storage/storage"
+	"my-gin/app/common/storage/local"
+	"my-gin/app/common/storage/s3"
+	"my-gin/app/common/storage/signed"
+	"my-gin/config"
 	"my-gin/global"
+	"sort"
+	"sync/atomic"
 )
 
-func InitializeStorage() {
-	_, _ = local.Init(global.App.Config.Storage.Disks.Local)
+// 未配置 storage.download_url 时使用的下载接口地址
+const defaultDownloadUrl = "/api/download"
+
+// 驱动是否已初始化，初始化前的配置热更新不校验驱动
+var storageInitialized atomic.Bool
+
+// InitializeStorage 初始化本地及已配置的 S3 驱动，默认驱动或上传策略使用的驱动不可用时返回错误
+func InitializeStorage() error {
+	storageConfig := global.App.Config().Storage
+	global.App.UrlSigner = newUrlSigner()
+
+	if _, err := local.Init(storageConfig.Disks.Local, global.App.UrlSigner); err != nil {
+		return err
+	}
+	if storageConfig.Disks.S3.Endpoint != "" {
+		if _, err := s3.Init(storageConfig.Disks.S3); err != nil {
+			return err
+		}
+	}
+	if err := checkDisks(global.App.Config()); err != nil {
+		return err
+	}
+	storageInitialized.Store(true)
+	return nil
+}
+
+// 校验 storage.default 及上传策略使用的驱动均已注册，驱动只在启动时初始化，热更新不能启用新的驱动
+func checkDisks(conf *config.Configuration) error {
+	if _, err := storage.Disk(conf.Storage.Default); err != nil {
+		return fmt.Errorf("storage.default: disk %s is not configured", conf.Storage.Default)
+	}
+	businesses := make([]string, 0, len(conf.Upload.Policies))
+	for business := range conf.Upload.Policies {
+		businesses = append(businesses, business)
+	}
+	sort.Strings(businesses)
+	for _, business := range businesses {
+		disk := conf.Upload.Policies[business].Disk
+		if disk == "" {
+			continue
+		}
+		if _, err := storage.Disk(storage.DiskName(disk)); err != nil {
+			return fmt.Errorf("upload.policies.%s.disk: disk %s is not configured", business, disk)
+		}
+	}
+	return nil
+}
+
+func newUrlSigner() *signed.Signer {
+	secret := global.App.Config().Storage.SignSecret
+	if secret == "" {
+		secret = global.App.Config().Jwt.Secret
+	}
+	downloadUrl := global.App.Config().Storage.DownloadUrl
+	if downloadUrl == "" {
+		downloadUrl = defaultDownloadUrl
+	}
+	return signed.NewSigner(secret, downloadUrl)
 }
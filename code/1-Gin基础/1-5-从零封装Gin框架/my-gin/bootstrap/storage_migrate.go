@@ -0,0 +1,74 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"my-gin/app/service"
+	"my-gin/global"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// MigrateStorage 将 Media 记录引用的文件迁移到另一个存储驱动，需在 InitializeConfig 后调用
+// 用法：my-gin storage migrate -from local -to s3 [-workers 4] [-batch 100] [-dry-run]
+func MigrateStorage(args []string, w io.Writer) error {
+	flags := flag.NewFlagSet("storage migrate", flag.ContinueOnError)
+	flags.SetOutput(w)
+	var opts service.MigrateOptions
+	flags.StringVar(&opts.From, "from", "", "源存储驱动，如 local")
+	flags.StringVar(&opts.To, "to", "", "目标存储驱动，如 s3")
+	flags.IntVar(&opts.Workers, "workers", 4, "并行复制的文件数")
+	flags.IntVar(&opts.BatchSize, "batch", 100, "每批处理的 Media 记录数")
+	flags.BoolVar(&opts.DryRun, "dry-run", false, "仅统计需迁移的文件，不复制、不更新记录")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if opts.From == "" || opts.To == "" {
+		flags.Usage()
+		return errors.New("-from and -to are required")
+	}
+
+	global.App.Log = InitializeLog()
+	defer CloseLog()
+
+	db, err := InitializeDB()
+	if err != nil {
+		return err
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+	// Redis 不可用时仅无法清除缓存的 url，缓存过期后自动失效
+	redis, _ := InitializeRedis()
+	if redis != nil {
+		defer redis.Close()
+	}
+	if err = InitializeStorage(); err != nil {
+		return err
+	}
+
+	// 中断后已复制的文件保留，重新执行时跳过
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	migrator := service.NewStorageMigrator(db, redis, global.App.Disk, global.App.ModuleLog(global.LogModuleStorage))
+	report, err := migrator.Migrate(ctx, opts)
+
+	if opts.DryRun {
+		fmt.Fprintf(w, "dry run: %d records, %d objects, %d bytes to migrate from %s to %s\n",
+			report.Records, report.Objects, report.Bytes, opts.From, opts.To)
+	} else {
+		fmt.Fprintf(w, "records: %d, objects: %d, bytes: %d, copied: %d, skipped: %d, updated: %d, failed: %d\n",
+			report.Records, report.Objects, report.Bytes, report.Copied, report.Skipped, report.Updated, len(report.Failed))
+		for _, failure := range report.Failed {
+			fmt.Fprintf(w, "failed: %s: %v\n", failure.Key, failure.Err)
+		}
+	}
+	if err == nil && len(report.Failed) > 0 {
+		err = fmt.Errorf("%d objects failed to migrate", len(report.Failed))
+	}
+	return err
+}
@@ -0,0 +1,90 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.uber.org/zap"
+	"my-gin/global"
+	"os"
+)
+
+var (
+	traceProvider *sdktrace.TracerProvider
+	// 文件导出器写入的文件，trace provider 关闭后关闭
+	traceFile *os.File
+)
+
+// InitializeTrace 初始化 OpenTelemetry，需在数据库及 Redis 初始化前调用
+func InitializeTrace() {
+	traceConfig := global.App.Config().Trace
+	// 无论是否启用，都使用 W3C trace-context 透传上游链路
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if !traceConfig.Enable {
+		return
+	}
+
+	exporter, err := getTraceExporter()
+	if err != nil {
+		global.App.Log.Error("trace exporter init failed", zap.Any("err", err))
+		return
+	}
+
+	res := resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(global.App.Config().App.AppName),
+		semconv.DeploymentEnvironment(global.App.Config().App.Env),
+	)
+
+	traceProvider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(traceConfig.SampleRatio))),
+	)
+	otel.SetTracerProvider(traceProvider)
+}
+
+func getTraceExporter() (sdktrace.SpanExporter, error) {
+	traceConfig := global.App.Config().Trace
+	switch traceConfig.Exporter {
+	case "otlp":
+		options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(traceConfig.Endpoint)}
+		if traceConfig.Insecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(context.Background(), options...)
+	case "file":
+		file, err := os.OpenFile(global.App.Config().Log.RootDir+"/"+traceConfig.Filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+		if err != nil {
+			return nil, err
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+		traceFile = file
+		return exporter, nil
+	default:
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+	}
+}
+
+// CloseTrace 导出剩余 span 并关闭 trace provider
+func CloseTrace(ctx context.Context) error {
+	if traceProvider == nil {
+		return nil
+	}
+	err := traceProvider.Shutdown(ctx)
+	if traceFile != nil {
+		err = errors.Join(err, traceFile.Close())
+		traceFile = nil
+	}
+	return err
+}
@@ -0,0 +1,7 @@
+package config
+
+type Admin struct {
+	Token string `mapstructure:"token" json:"token" yaml:"token"`
+	Host  string `mapstructure:"host" json:"host" yaml:"host" validate:"omitempty,hostname|ip"` // 管理端口监听的地址，为空时只监听 127.0.0.1
+	Port  string `mapstructure:"port" json:"port" yaml:"port" validate:"omitempty,numeric"`     // 管理接口单独监听的端口，为空时与业务接口共用端口
+}
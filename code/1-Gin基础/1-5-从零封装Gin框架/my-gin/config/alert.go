@@ -0,0 +1,10 @@
+package config
+
+type Alert struct {
+	DedupWindow  int    `mapstructure:"dedup_window" json:"dedup_window" yaml:"dedup_window" validate:"gte=0"`       // 相同告警去重窗口 秒
+	MaxPerMinute int    `mapstructure:"max_per_minute" json:"max_per_minute" yaml:"max_per_minute" validate:"gte=0"` // 每分钟最多发送告警数，0 为不限制
+	WebhookUrl   string `mapstructure:"webhook_url" json:"webhook_url" yaml:"webhook_url" validate:"omitempty,url"`  // 告警 webhook 地址，为空时仅写入日志
+	QueueSize    int    `mapstructure:"queue_size" json:"queue_size" yaml:"queue_size" validate:"gte=0"`             // 告警队列长度，队列满时丢弃，0 时使用默认值 100
+	Workers      int    `mapstructure:"workers" json:"workers" yaml:"workers" validate:"gte=0"`                      // 发送协程数，0 时使用默认值 2
+	Retry        int    `mapstructure:"retry" json:"retry" yaml:"retry" validate:"gte=0"`                            // 发送失败重试次数，按 1s、2s、4s... 退避
+}
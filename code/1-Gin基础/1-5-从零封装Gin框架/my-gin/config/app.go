@@ -1,8 +1,15 @@
 package config
 
+// 错误响应格式
+const (
+	ErrorFormatLegacy  = "legacy"  // HTTP 状态码恒为 200，通过 error_code 区分错误
+	ErrorFormatProblem = "problem" // RFC 7807 application/problem+json，使用错误对应的 HTTP 状态码
+)
+
 type App struct {
-	Env     string `mapstructure:"env" json:"env" yaml:"env"`
-	Port    string `mapstructure:"port" json:"port" yaml:"port"`
-	AppName string `mapstructure:"app_name" json:"app_name" yaml:"app_name"`
-	AppUrl  string `mapstructure:"app_url" json:"app_url" yaml:"app_url"`
+	Env         string `mapstructure:"env" json:"env" yaml:"env" validate:"required"`
+	Port        string `mapstructure:"port" json:"port" yaml:"port" validate:"required,numeric"`
+	AppName     string `mapstructure:"app_name" json:"app_name" yaml:"app_name"`
+	AppUrl      string `mapstructure:"app_url" json:"app_url" yaml:"app_url" validate:"omitempty,url"`
+	ErrorFormat string `mapstructure:"error_format" json:"error_format" yaml:"error_format" validate:"omitempty,oneof=legacy problem"`
 }
@@ -2,9 +2,17 @@ package config
 
 type Configuration struct {
 	App      App      `mapstructure:"app" json:"app" yaml:"app"`
+	Server   Server   `mapstructure:"server" json:"server" yaml:"server"`
 	Log      Log      `mapstructure:"log" json:"log" yaml:"log"`
+	Admin    Admin    `mapstructure:"admin" json:"admin" yaml:"admin"`
+	Cors     Cors     `mapstructure:"cors" json:"cors" yaml:"cors"`
+	Alert    Alert    `mapstructure:"alert" json:"alert" yaml:"alert"`
+	Metrics  Metrics  `mapstructure:"metrics" json:"metrics" yaml:"metrics"`
+	Trace    Trace    `mapstructure:"trace" json:"trace" yaml:"trace"`
+	Health   Health   `mapstructure:"health" json:"health" yaml:"health"`
 	Database Database `mapstructure:"database" json:"database" yaml:"database"`
 	Jwt      Jwt      `mapstructure:"jwt" json:"jwt" yaml:"jwt"`
 	Redis    Redis    `mapstructure:"redis" json:"redis" yaml:"redis"`
 	Storage  Storage  `mapstructure:"storage" json:"storage" yaml:"storage"`
+	Upload   Upload   `mapstructure:"upload" json:"upload" yaml:"upload"`
 }
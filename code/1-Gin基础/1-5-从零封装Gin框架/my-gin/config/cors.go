@@ -0,0 +1,5 @@
+package config
+
+type Cors struct {
+	AllowOrigins []string `mapstructure:"allow_origins" json:"allow_origins" yaml:"allow_origins" validate:"dive,required,http_url"` // 允许跨域的来源，需包含 http:// 或 https://，为空时允许所有来源
+}
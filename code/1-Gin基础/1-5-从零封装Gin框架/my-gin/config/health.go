@@ -0,0 +1,8 @@
+package config
+
+type Health struct {
+	CacheTTL      int            `mapstructure:"cache_ttl" json:"cache_ttl" yaml:"cache_ttl" validate:"gte=0"`                // 检查结果缓存时间 毫秒
+	Timeout       int            `mapstructure:"timeout" json:"timeout" yaml:"timeout" validate:"gte=0"`                      // 单项检查默认超时时间 毫秒
+	Timeouts      map[string]int `mapstructure:"timeouts" json:"timeouts" yaml:"timeouts" validate:"dive,gte=0"`              // 单项检查超时时间覆盖，如 db: 2000
+	ShutdownDelay int            `mapstructure:"shutdown_delay" json:"shutdown_delay" yaml:"shutdown_delay" validate:"gte=0"` // 就绪检查失败后等待多久再关闭服务 秒
+}
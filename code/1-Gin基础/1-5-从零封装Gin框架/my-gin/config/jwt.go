@@ -1,8 +1,8 @@
 package config
 
 type Jwt struct {
-	Secret                  string `mapstructure:"secret" json:"secret" yaml:"secret"`
-	JwtTtl                  int64  `mapstructure:"jwt_ttl" json:"jwt_ttl" yaml:"jwt_ttl"`
-	JwtBlacklistGracePeriod int64  `mapstructure:"jwt_blacklist_grace_period" json:"jwt_blacklist_grace_period" yaml:"jwt_blacklist_grace_period"`
-	RefreshGracePeriod      int64  `mapstructure:"refresh_grace_period" json:"refresh_grace_period" yaml:"refresh_grace_period"` // token 自动刷新宽限时间（秒）
+	Secret                  string `mapstructure:"secret" json:"secret" yaml:"secret" validate:"required"`
+	JwtTtl                  int64  `mapstructure:"jwt_ttl" json:"jwt_ttl" yaml:"jwt_ttl" validate:"gt=0"`
+	JwtBlacklistGracePeriod int64  `mapstructure:"jwt_blacklist_grace_period" json:"jwt_blacklist_grace_period" yaml:"jwt_blacklist_grace_period" validate:"gte=0"`
+	RefreshGracePeriod      int64  `mapstructure:"refresh_grace_period" json:"refresh_grace_period" yaml:"refresh_grace_period" validate:"gte=0"` // token 自动刷新宽限时间（秒）
 }
@@ -1,13 +1,47 @@
 package config
 
 type Log struct {
-	Level      string `mapstructure:"level" json:"level" yaml:"level"`
-	RootDir    string `mapstructure:"root_dir" json:"root_dir" yaml:"root_dir"`
-	Filename   string `mapstructure:"filename" json:"filename" yaml:"filename"`
-	Format     string `mapstructure:"format" json:"format" yaml:"format"`
-	ShowLine   bool   `mapstructure:"show_line" json:"show_line" yaml:"show_line"`
-	MaxBackups int    `mapstructure:"max_backups" json:"max_backups" yaml:"max_backups"`
-	MaxSize    int    `mapstructure:"max_size" json:"max_size" yaml:"max_size"`
-	MaxAge     int    `mapstructure:"max_age" json:"max_age" yaml:"max_age"`
-	Compress   bool   `mapstructure:"compress" json:"compress" yaml:"compress"`
+	Level         string            `mapstructure:"level" json:"level" yaml:"level" validate:"omitempty,oneof=debug info warn error dpanic panic fatal"`
+	RootDir       string            `mapstructure:"root_dir" json:"root_dir" yaml:"root_dir" validate:"required"`
+	Filename      string            `mapstructure:"filename" json:"filename" yaml:"filename" validate:"required"`
+	ErrorFilename string            `mapstructure:"error_filename" json:"error_filename" yaml:"error_filename"` // error 及以上等级单独写入的文件，为空时不拆分
+	Format        string            `mapstructure:"format" json:"format" yaml:"format" validate:"omitempty,oneof=json console"`
+	ShowLine      bool              `mapstructure:"show_line" json:"show_line" yaml:"show_line"`
+	MaxBackups    int               `mapstructure:"max_backups" json:"max_backups" yaml:"max_backups" validate:"gte=0"`
+	MaxSize       int               `mapstructure:"max_size" json:"max_size" yaml:"max_size" validate:"gte=0"`
+	MaxAge        int               `mapstructure:"max_age" json:"max_age" yaml:"max_age" validate:"gte=0"`
+	Compress      bool              `mapstructure:"compress" json:"compress" yaml:"compress"`
+	DailyRotate   bool              `mapstructure:"daily_rotate" json:"daily_rotate" yaml:"daily_rotate"` // 是否在按大小切割的同时按天切割
+	Async         LogAsync          `mapstructure:"async" json:"async" yaml:"async"`
+	Sinks         []LogSink         `mapstructure:"sinks" json:"sinks" yaml:"sinks" validate:"dive"`
+	Modules       map[string]string `mapstructure:"modules" json:"modules" yaml:"modules" validate:"dive,oneof=debug info warn error dpanic panic fatal"` // 模块日志等级覆盖，如 db: debug
+	AccessLog     AccessLog         `mapstructure:"access_log" json:"access_log" yaml:"access_log"`
+	Redact        LogRedact         `mapstructure:"redact" json:"redact" yaml:"redact"`
+}
+
+// LogAsync 缓冲写入，程序退出时刷新
+type LogAsync struct {
+	Enable        bool `mapstructure:"enable" json:"enable" yaml:"enable"`
+	BufferSize    int  `mapstructure:"buffer_size" json:"buffer_size" yaml:"buffer_size" validate:"gte=0"`          // 缓冲区大小 KB
+	FlushInterval int  `mapstructure:"flush_interval" json:"flush_interval" yaml:"flush_interval" validate:"gte=0"` // 刷新间隔 秒
+}
+
+// LogSink 额外的日志输出，统一使用 json 格式
+type LogSink struct {
+	Type    string `mapstructure:"type" json:"type" yaml:"type" validate:"oneof=syslog tcp udp"` // syslog / tcp / udp
+	Network string `mapstructure:"network" json:"network" yaml:"network"`                        // syslog 网络类型，为空时写入本机 syslog
+	Address string `mapstructure:"address" json:"address" yaml:"address" validate:"required_unless=Type syslog"`
+	Tag     string `mapstructure:"tag" json:"tag" yaml:"tag"`                                                                           // syslog tag
+	Level   string `mapstructure:"level" json:"level" yaml:"level" validate:"omitempty,oneof=debug info warn error dpanic panic fatal"` // 最低写入等级
+}
+
+// LogRedact 在默认规则（密码、手机号、令牌、身份证号等）基础上追加的脱敏配置
+type LogRedact struct {
+	Fields   []string `mapstructure:"fields" json:"fields" yaml:"fields"`                                // 字段名，不区分大小写
+	Patterns []string `mapstructure:"patterns" json:"patterns" yaml:"patterns" validate:"dive,required"` // 正则，匹配内容整体替换
+}
+
+type AccessLog struct {
+	SkipPaths  []string `mapstructure:"skip_paths" json:"skip_paths" yaml:"skip_paths"`                           // 不记录访问日志的路径
+	SampleRate float64  `mapstructure:"sample_rate" json:"sample_rate" yaml:"sample_rate" validate:"gte=0,lte=1"` // 正常请求采样率 (0, 1]，未配置时全部记录
 }
@@ -0,0 +1,6 @@
+package config
+
+type Metrics struct {
+	Enable bool   `mapstructure:"enable" json:"enable" yaml:"enable"`
+	Path   string `mapstructure:"path" json:"path" yaml:"path" validate:"omitempty,startswith=/"`
+}
@@ -1,8 +1,8 @@
 package config
 
 type Redis struct {
-	Host     string `mapstructure:"host" json:"host" yaml:"host"`
-	Port     int    `mapstructure:"port" json:"port" yaml:"port"`
-	DB       int    `mapstructure:"db" json:"db" yaml:"db"`
+	Host     string `mapstructure:"host" json:"host" yaml:"host" validate:"required"`
+	Port     int    `mapstructure:"port" json:"port" yaml:"port" validate:"gt=0,lte=65535"`
+	DB       int    `mapstructure:"db" json:"db" yaml:"db" validate:"gte=0"`
 	Password string `mapstructure:"password" json:"password" yaml:"password"`
 }
@@ -0,0 +1,23 @@
+package config
+
+type Server struct {
+	UnixSocket        string    `mapstructure:"unix_socket" json:"unix_socket" yaml:"unix_socket"`                                          // 监听的 unix socket 路径，配置后不再监听 app.port
+	ReadTimeout       int       `mapstructure:"read_timeout" json:"read_timeout" yaml:"read_timeout" validate:"gte=0"`                      // 读取整个请求的超时时间 秒
+	ReadHeaderTimeout int       `mapstructure:"read_header_timeout" json:"read_header_timeout" yaml:"read_header_timeout" validate:"gte=0"` // 读取请求头的超时时间 秒
+	WriteTimeout      int       `mapstructure:"write_timeout" json:"write_timeout" yaml:"write_timeout" validate:"gte=0"`                   // 写入响应的超时时间 秒
+	IdleTimeout       int       `mapstructure:"idle_timeout" json:"idle_timeout" yaml:"idle_timeout" validate:"gte=0"`                      // keep-alive 空闲超时时间 秒
+	MaxHeaderBytes    int       `mapstructure:"max_header_bytes" json:"max_header_bytes" yaml:"max_header_bytes" validate:"gte=0"`          // 请求头最大字节数
+	ShutdownTimeout   int       `mapstructure:"shutdown_timeout" json:"shutdown_timeout" yaml:"shutdown_timeout" validate:"gte=0"`          // 优雅关闭超时时间 秒
+	H2C               bool      `mapstructure:"h2c" json:"h2c" yaml:"h2c"`                                                                  // 未启用 TLS 时是否支持明文 HTTP/2
+	PidFile           string    `mapstructure:"pid_file" json:"pid_file" yaml:"pid_file"`                                                   // 进程 pid 文件路径，为空时不写入
+	RestartTimeout    int       `mapstructure:"restart_timeout" json:"restart_timeout" yaml:"restart_timeout" validate:"gte=0"`             // 平滑重启时等待新进程就绪的超时时间 秒
+	Tls               ServerTls `mapstructure:"tls" json:"tls" yaml:"tls"`
+}
+
+type ServerTls struct {
+	Enable       bool   `mapstructure:"enable" json:"enable" yaml:"enable"`
+	CertFile     string `mapstructure:"cert_file" json:"cert_file" yaml:"cert_file" validate:"required_if=Enable true"`
+	KeyFile      string `mapstructure:"key_file" json:"key_file" yaml:"key_file" validate:"required_if=Enable true"`
+	ClientCaFile string `mapstructure:"client_ca_file" json:"client_ca_file" yaml:"client_ca_file"`                                                   // 客户端证书 CA，配置后启用 mTLS
+	ClientAuth   string `mapstructure:"client_auth" json:"client_auth" yaml:"client_auth" validate:"omitempty,oneof=request verify_if_given require"` // 客户端证书校验方式 request / verify_if_given / require，默认 require
+}
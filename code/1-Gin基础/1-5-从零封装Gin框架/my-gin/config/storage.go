@@ -3,15 +3,28 @@ package config
 import (
 	"github.com/jassue/go-storage/local"
 	"github.com/jassue/go-storage/storage"
+	"my-gin/app/common/storage/s3"
 )
 
 type Storage struct {
-	Default storage.DiskName `mapstructure:"default" json:"default" yaml:"default"`
-	Disks   Disks            `mapstructure:"disks" json:"disks" yaml:"disks"`
+	Default         storage.DiskName `mapstructure:"default" json:"default" yaml:"default" validate:"required,oneof=local s3"`
+	Disks           Disks            `mapstructure:"disks" json:"disks" yaml:"disks"`
+	SignSecret      string           `mapstructure:"sign_secret" json:"sign_secret" yaml:"sign_secret"`
+	DownloadUrl     string           `mapstructure:"download_url" json:"download_url" yaml:"download_url" validate:"omitempty,url|startswith=/"`
+	TemporaryUrlTtl int64            `mapstructure:"temporary_url_ttl" json:"temporary_url_ttl" yaml:"temporary_url_ttl" validate:"gte=0"`
+	Gc              StorageGc        `mapstructure:"gc" json:"gc" yaml:"gc"`
+}
+
+// StorageGc 孤儿文件（无 Media 记录引用的文件）清理
+type StorageGc struct {
+	Interval    int64 `mapstructure:"interval" json:"interval" yaml:"interval" validate:"gte=0"`             // 检查间隔 秒，0 表示不启用
+	GracePeriod int64 `mapstructure:"grace_period" json:"grace_period" yaml:"grace_period" validate:"gte=0"` // 修改时间超过该时长的文件才视为孤儿文件 秒，默认 86400
+	Delete      bool  `mapstructure:"delete" json:"delete" yaml:"delete"`                                    // 是否删除孤儿文件，否则仅记录日志
 }
 
 type Disks struct {
 	Local local.Config `mapstructure:"local" json:"local" yaml:"local"`
+	S3    s3.Config    `mapstructure:"s3" json:"s3" yaml:"s3"`
 	//AliOss oss.Config   `mapstructure:"ali_oss" json:"ali_oss" yaml:"ali_oss"`
 	//QiNiu  kodo.Config  `mapstructure:"qi_niu" json:"qi_niu" yaml:"qi_niu"`
 }
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Store 保存当前配置快照，热更新时整体替换
+// 快照一经发布不可修改，读取方拿到的始终是一份完整、已校验的配置
+type Store struct {
+	current atomic.Pointer[Configuration]
+
+	mu          sync.Mutex
+	subscribers []subscriber
+}
+
+type subscriber struct {
+	keys []string
+	fn   func(old, new *Configuration)
+}
+
+// NewStore 以初始配置创建配置存储
+func NewStore(c *Configuration) *Store {
+	s := &Store{}
+	s.current.Store(c)
+	return s
+}
+
+// Load 获取当前配置快照
+func (s *Store) Load() *Configuration {
+	return s.current.Load()
+}
+
+// Swap 发布新的配置快照，并通知订阅的配置项发生变化的订阅者
+func (s *Store) Swap(c *Configuration) *Configuration {
+	old := s.current.Swap(c)
+
+	s.mu.Lock()
+	subscribers := append([]subscriber(nil), s.subscribers...)
+	s.mu.Unlock()
+
+	for _, sub := range subscribers {
+		if changed(old, c, sub.keys) {
+			sub.fn(old, c)
+		}
+	}
+	return old
+}
+
+// Subscribe 订阅配置变更，keys 为 mapstructure 路径，如 log.level、cors，为空时订阅全部变更
+func (s *Store) Subscribe(fn func(old, new *Configuration), keys ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers = append(s.subscribers, subscriber{keys: keys, fn: fn})
+}
+
+func changed(old, next *Configuration, keys []string) bool {
+	if old == nil {
+		return true
+	}
+	if len(keys) == 0 {
+		return !reflect.DeepEqual(old, next)
+	}
+	for _, key := range keys {
+		if !reflect.DeepEqual(lookup(old, key), lookup(next, key)) {
+			return true
+		}
+	}
+	return false
+}
+
+// 按 mapstructure 路径取配置项的值，路径不存在时返回 nil
+func lookup(c *Configuration, key string) any {
+	value := reflect.ValueOf(c).Elem()
+	for _, name := range strings.Split(key, ".") {
+		if value.Kind() != reflect.Struct {
+			return nil
+		}
+		found := false
+		for i := 0; i < value.NumField(); i++ {
+			if tagName(value.Type().Field(i)) == name {
+				value, found = value.Field(i), true
+				break
+			}
+		}
+		if !found {
+			return nil
+		}
+	}
+	return value.Interface()
+}
+
+func tagName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("mapstructure"), ",")[0]
+	if name == "" {
+		return strings.ToLower(field.Name)
+	}
+	return name
+}
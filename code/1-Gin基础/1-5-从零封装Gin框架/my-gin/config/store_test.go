@@ -0,0 +1,81 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	conf := &Configuration{
+		App: App{ErrorFormat: ErrorFormatProblem},
+		Log: Log{Level: "debug", Async: LogAsync{BufferSize: 256}},
+	}
+
+	tests := []struct {
+		key  string
+		want any
+	}{
+		{"app.error_format", ErrorFormatProblem},
+		{"log.level", "debug"},
+		{"log.async.buffer_size", 256},
+		{"log.async", LogAsync{BufferSize: 256}},
+		{"log.unknown", nil},
+		{"unknown", nil},
+		{"log.level.value", nil}, // 路径穿过非结构体字段
+		{"Log.Level", nil},       // 按 mapstructure 名称匹配，区分大小写
+	}
+	for _, tt := range tests {
+		if got := lookup(conf, tt.key); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("lookup(%q) = %#v, want %#v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestChanged(t *testing.T) {
+	old := &Configuration{Log: Log{Level: "info"}, Cors: Cors{AllowOrigins: []string{"http://a.com"}}}
+	next := &Configuration{Log: Log{Level: "debug"}, Cors: Cors{AllowOrigins: []string{"http://a.com"}}}
+
+	tests := []struct {
+		name string
+		old  *Configuration
+		keys []string
+		want bool
+	}{
+		{"initial", nil, []string{"cors"}, true},
+		{"all keys", old, nil, true},
+		{"changed key", old, []string{"log.level"}, true},
+		{"changed parent", old, []string{"log"}, true},
+		{"unchanged key", old, []string{"cors"}, false},
+		{"unchanged sibling", old, []string{"log.format"}, false},
+		{"unknown key", old, []string{"log.unknown"}, false},
+		{"any of keys", old, []string{"cors", "log.level"}, true},
+	}
+	for _, tt := range tests {
+		if got := changed(tt.old, next, tt.keys); got != tt.want {
+			t.Errorf("%s: changed = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStoreSubscribe(t *testing.T) {
+	store := NewStore(&Configuration{Log: Log{Level: "info"}})
+	var levelCalls, corsCalls int
+	store.Subscribe(func(old, new *Configuration) {
+		levelCalls++
+		if old.Log.Level != "info" || new.Log.Level != "debug" {
+			t.Errorf("got %s -> %s, want info -> debug", old.Log.Level, new.Log.Level)
+		}
+	}, "log.level")
+	store.Subscribe(func(_, _ *Configuration) { corsCalls++ }, "cors")
+
+	next := &Configuration{Log: Log{Level: "debug"}}
+	if old := store.Swap(next); old.Log.Level != "info" {
+		t.Errorf("Swap returned %s, want info", old.Log.Level)
+	}
+	if store.Load() != next {
+		t.Error("Load does not return the swapped config")
+	}
+	if levelCalls != 1 || corsCalls != 0 {
+		t.Errorf("got %d log.level and %d cors notifications, want 1 and 0", levelCalls, corsCalls)
+	}
+}
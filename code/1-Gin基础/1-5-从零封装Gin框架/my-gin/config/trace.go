@@ -0,0 +1,10 @@
+package config
+
+type Trace struct {
+	Enable      bool    `mapstructure:"enable" json:"enable" yaml:"enable"`
+	Exporter    string  `mapstructure:"exporter" json:"exporter" yaml:"exporter" validate:"required_if=Enable true,omitempty,oneof=otlp stdout file"` // otlp / stdout / file
+	Endpoint    string  `mapstructure:"endpoint" json:"endpoint" yaml:"endpoint"`                                                                     // otlp http 地址，如 127.0.0.1:4318
+	Insecure    bool    `mapstructure:"insecure" json:"insecure" yaml:"insecure"`                                                                     // otlp 是否使用 http 明文传输
+	Filename    string  `mapstructure:"filename" json:"filename" yaml:"filename"`                                                                     // file 导出文件名，位于日志根目录
+	SampleRatio float64 `mapstructure:"sample_ratio" json:"sample_ratio" yaml:"sample_ratio" validate:"gte=0,lte=1"`                                  // 采样率 [0, 1]
+}
@@ -0,0 +1,35 @@
+package config
+
+const (
+	// DefaultChunkTtl 未配置 chunk_ttl 时分片上传的过期时间 秒
+	DefaultChunkTtl = 86400
+	// UploadKeepAlive 上传记录在过期后保留的时间 秒，清理任务需在此期间内完成清理
+	UploadKeepAlive = 3600
+)
+
+type Upload struct {
+	Policies        map[string]UploadPolicy `mapstructure:"policies" json:"policies" yaml:"policies" validate:"dive"`                          // 按业务类型配置的上传策略，键为 business
+	ChunkSize       int64                   `mapstructure:"chunk_size" json:"chunk_size" yaml:"chunk_size" validate:"gte=0"`                   // 分片上传的分片大小 字节
+	ChunkTtl        int64                   `mapstructure:"chunk_ttl" json:"chunk_ttl" yaml:"chunk_ttl" validate:"gte=0"`                      // 分片上传无操作多久后过期 秒
+	CleanupInterval int64                   `mapstructure:"cleanup_interval" json:"cleanup_interval" yaml:"cleanup_interval" validate:"gte=0"` // 过期分片上传的清理间隔 秒
+}
+
+type UploadPolicy struct {
+	Types      []string `mapstructure:"types" json:"types" yaml:"types" validate:"min=1,dive,required"`                           // 允许的 MIME 类型，按文件内容识别，支持 image/* 形式
+	MaxSize    int64    `mapstructure:"max_size" json:"max_size" yaml:"max_size" validate:"gte=0"`                                // 最大文件大小 字节，0 表示不限制
+	MaxWidth   int      `mapstructure:"max_width" json:"max_width" yaml:"max_width" validate:"gte=0"`                             // 图片最大宽度 像素，0 表示不限制
+	MaxHeight  int      `mapstructure:"max_height" json:"max_height" yaml:"max_height" validate:"gte=0"`                          // 图片最大高度 像素，0 表示不限制
+	Disk       string   `mapstructure:"disk" json:"disk" yaml:"disk" validate:"omitempty,oneof=local s3"`                         // 存储驱动，为空时使用 storage.default
+	Visibility string   `mapstructure:"visibility" json:"visibility" yaml:"visibility" validate:"omitempty,oneof=public private"` // 可见性，为空时由上传参数决定，默认公开
+	// 图片处理
+	KeepMetadata bool                    `mapstructure:"keep_metadata" json:"keep_metadata" yaml:"keep_metadata"`                        // 是否保留图片 EXIF（含 GPS）等元数据，默认去除
+	Variants     map[string]ImageVariant `mapstructure:"variants" json:"variants" yaml:"variants" validate:"dive,keys,alphanum,endkeys"` // 缩略图，键为缩略图名称
+}
+
+type ImageVariant struct {
+	Width   int    `mapstructure:"width" json:"width" yaml:"width" validate:"gte=0,required_without=Height"`    // 宽度 像素，为 0 时按高度等比缩放
+	Height  int    `mapstructure:"height" json:"height" yaml:"height" validate:"gte=0"`                         // 高度 像素，为 0 时按宽度等比缩放
+	Fit     string `mapstructure:"fit" json:"fit" yaml:"fit" validate:"omitempty,oneof=contain cover"`          // 缩放方式，contain 等比缩放至不超过宽高，cover 缩放后居中裁剪，默认 contain
+	Format  string `mapstructure:"format" json:"format" yaml:"format" validate:"omitempty,oneof=jpeg png webp"` // 输出格式，默认 jpeg，webp 为无损压缩
+	Quality int    `mapstructure:"quality" json:"quality" yaml:"quality" validate:"omitempty,min=1,max=100"`    // jpeg 压缩质量 1-100，默认 85
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"strings"
+)
+
+var configValidator = newConfigValidator()
+
+func newConfigValidator() *validator.Validate {
+	v := validator.New()
+	// 错误信息使用配置文件中的字段名
+	v.RegisterTagNameFunc(tagName)
+	v.RegisterStructValidation(validateUpload, Upload{})
+	return v
+}
+
+// 清理间隔需小于上传记录的保留时间（chunk_ttl + 3600 秒），否则记录过期后才清理
+func validateUpload(sl validator.StructLevel) {
+	upload := sl.Current().Interface().(Upload)
+	chunkTtl := upload.ChunkTtl
+	if chunkTtl == 0 {
+		chunkTtl = DefaultChunkTtl
+	}
+	if upload.CleanupInterval >= chunkTtl+UploadKeepAlive {
+		sl.ReportError(upload.CleanupInterval, "cleanup_interval", "CleanupInterval", "lt", "chunk_ttl+3600")
+	}
+}
+
+// Validate 按 validate tag 校验配置，返回所有不合法的配置项
+func (c *Configuration) Validate() error {
+	err := configValidator.Struct(c)
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+
+	messages := make([]string, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		// Configuration.log.level -> log.level
+		key := fieldErr.Namespace()
+		if i := strings.IndexByte(key, '.'); i >= 0 {
+			key = key[i+1:]
+		}
+		rule := fieldErr.Tag()
+		if fieldErr.Param() != "" {
+			rule += "=" + fieldErr.Param()
+		}
+		// 不输出配置值，避免密钥写入日志
+		messages = append(messages, fmt.Sprintf("%s: does not satisfy %s", key, rule))
+	}
+	return errors.New("invalid config: " + strings.Join(messages, "; "))
+}
@@ -6,28 +6,41 @@ import (
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"my-gin/app/common/alert"
+	"my-gin/app/common/health"
+	"my-gin/app/common/lifecycle"
+	"my-gin/app/common/storage/signed"
 	"my-gin/config"
+	"my-gin/utils"
 )
 
 type Application struct {
 	ConfigViper *viper.Viper
-	Config      config.Configuration
+	ConfigStore *config.Store
 	Log         *zap.Logger
+	LogLevels   *LogLevels
+	Lifecycle   *lifecycle.Lifecycle
+	Redactor    *utils.Redactor
+	Alert       *alert.Dispatcher
+	Health      *health.Registry
+	UrlSigner   *signed.Signer
 	DB          *gorm.DB
 	Redis       *redis.Client
 }
 
-var App = new(Application)
+var App = &Application{ConfigStore: config.NewStore(&config.Configuration{})}
 
-func (app *Application) Disk(disk ...string) storage.Storage {
+// Config 获取当前配置快照，热更新后返回新的快照，调用方不应修改返回的配置
+func (app *Application) Config() *config.Configuration {
+	return app.ConfigStore.Load()
+}
+
+// Disk 按名称获取文件系统，未传参时使用默认驱动，驱动未注册时返回错误
+func (app *Application) Disk(disk ...string) (storage.Storage, error) {
 	// 若未传参，默认使用配置文件驱动
-	diskName := app.Config.Storage.Default
+	diskName := app.Config().Storage.Default
 	if len(disk) > 0 {
 		diskName = storage.DiskName(disk[0])
 	}
-	s, err := storage.Disk(diskName)
-	if err != nil {
-		app.Log.Error("error to get the information of file: " + string(diskName))
-	}
-	return s
+	return storage.Disk(diskName)
 }
@@ -1,18 +1,140 @@
 package global
 
+import (
+	"fmt"
+	"net/http"
+)
+
+// CustomError 应用错误，服务层直接返回，由 ErrorHandler 中间件统一输出
+// 客户端按 ErrorCode 或 Key 区分错误，ErrorMsg 仅用于展示
 type CustomError struct {
-	ErrorCode int
-	ErrorMsg  string
+	ErrorCode  int
+	Key        string                 // 错误标识，如 media.not_found，可作为多语言文案的 key
+	ErrorMsg   string                 // 展示给用户的错误信息
+	HttpStatus int                    // 响应的 HTTP 状态码，仅 app.error_format 为 problem 时使用
+	Cause      error                  // 原始错误，仅记录日志，不输出给客户端
+	Details    map[string]interface{} // 附加信息，随错误响应输出
+}
+
+// Error 实现 error 接口，便于在服务层直接返回自定义错误
+func (e CustomError) Error() string {
+	if e.Cause != nil {
+		return e.ErrorMsg + ": " + e.Cause.Error()
+	}
+	return e.ErrorMsg
+}
+
+// Unwrap 支持 errors.Is/As 匹配原始错误
+func (e CustomError) Unwrap() error {
+	return e.Cause
+}
+
+// Is 错误码相同即视为同一错误，errors.Is(err, global.Errors.MediaNotFoundError) 不受 WithMsg 等影响
+func (e CustomError) Is(target error) bool {
+	t, ok := target.(CustomError)
+	return ok && t.ErrorCode == e.ErrorCode
+}
+
+// WithMsg 使用相同错误码、HTTP 状态码返回更具体的错误信息
+func (e CustomError) WithMsg(msg string) CustomError {
+	e.ErrorMsg = msg
+	return e
+}
+
+// WithCause 记录原始错误
+func (e CustomError) WithCause(err error) CustomError {
+	e.Cause = err
+	return e
+}
+
+// WithDetail 添加附加信息，不修改原错误
+func (e CustomError) WithDetail(key string, value interface{}) CustomError {
+	details := make(map[string]interface{}, len(e.Details)+1)
+	for k, v := range e.Details {
+		details[k] = v
+	}
+	details[key] = value
+	e.Details = details
+	return e
+}
+
+// 已注册的错误码及错误标识，避免重复
+var (
+	errorCodes = make(map[int]string)
+	errorKeys  = make(map[string]int)
+)
+
+// 注册错误，错误码或错误标识重复时 panic
+func newError(code int, key string, msg string, httpStatus int) CustomError {
+	if registered, ok := errorCodes[code]; ok {
+		panic(fmt.Sprintf("error code %d already registered by %s", code, registered))
+	}
+	if registered, ok := errorKeys[key]; ok {
+		panic(fmt.Sprintf("error key %s already registered by %d", key, registered))
+	}
+	errorCodes[code] = key
+	errorKeys[key] = code
+	return CustomError{ErrorCode: code, Key: key, ErrorMsg: msg, HttpStatus: httpStatus}
 }
 
 type CustomErrors struct {
-	BusinessError CustomError
-	ValidateError CustomError
-	TokenError    CustomError
+	BusinessError        CustomError
+	ValidateError        CustomError
+	TokenError           CustomError
+	ForbiddenError       CustomError
+	NotFoundError        CustomError
+	UploadBusinessError  CustomError
+	UploadTypeError      CustomError
+	UploadSizeError      CustomError
+	UploadDimensionError CustomError
+	ServerError          CustomError
+
+	// 用户
+	MobileExistsError CustomError
+	LoginError        CustomError
+	UserNotFoundError CustomError
+
+	// 媒体文件
+	MediaNotFoundError   CustomError
+	VariantNotFoundError CustomError
+	MediaBusyError       CustomError
+	UploadFailedError    CustomError
+
+	// 分片上传
+	UploadNotFoundError   CustomError
+	ChunkIndexError       CustomError
+	ChunkSizeError        CustomError
+	UploadCompletingError CustomError
+	UploadIncompleteError CustomError
+	UploadChecksumError   CustomError
 }
 
+// Errors 错误码注册表，错误码前三位与 HTTP 状态码对应
 var Errors = CustomErrors{
-	BusinessError: CustomError{40000, "业务错误"},
-	ValidateError: CustomError{42200, "请求参数错误"},
-	TokenError:    CustomError{40100, "登陆授权失败"},
+	BusinessError:        newError(40000, "business", "业务错误", http.StatusBadRequest),
+	ValidateError:        newError(42200, "validation", "请求参数错误", http.StatusUnprocessableEntity),
+	TokenError:           newError(40100, "token", "登陆授权失败", http.StatusUnauthorized),
+	ForbiddenError:       newError(40300, "forbidden", "没有访问权限", http.StatusForbidden),
+	NotFoundError:        newError(40400, "not_found", "资源不存在", http.StatusNotFound),
+	UploadBusinessError:  newError(42210, "upload.business", "不支持的上传业务类型", http.StatusUnprocessableEntity),
+	UploadTypeError:      newError(41501, "upload.type", "文件类型不允许", http.StatusUnsupportedMediaType),
+	UploadSizeError:      newError(41301, "upload.size", "文件大小超出限制", http.StatusRequestEntityTooLarge),
+	UploadDimensionError: newError(42213, "upload.dimension", "图片尺寸超出限制", http.StatusUnprocessableEntity),
+	ServerError:          newError(http.StatusInternalServerError, "server", "Internal Server Error", http.StatusInternalServerError),
+
+	MobileExistsError: newError(40900, "user.mobile_exists", "手机号已经存在", http.StatusConflict),
+	LoginError:        newError(40001, "user.login_failed", "用户名不存在或者密码错误", http.StatusBadRequest),
+	UserNotFoundError: newError(40401, "user.not_found", "数据不存在", http.StatusNotFound),
+
+	MediaNotFoundError:   newError(40402, "media.not_found", "文件不存在", http.StatusNotFound),
+	VariantNotFoundError: newError(40403, "media.variant_not_found", "缩略图不存在", http.StatusNotFound),
+	MediaBusyError:       newError(40901, "media.busy", "文件正在处理中，请稍后重试", http.StatusConflict),
+	UploadFailedError:    newError(50001, "upload.failed", "上传失败，请重试", http.StatusInternalServerError),
+
+	UploadNotFoundError:   newError(40404, "upload.not_found", "上传不存在或已过期", http.StatusNotFound),
+	ChunkIndexError:       newError(42214, "upload.chunk_index", "分片序号不正确", http.StatusUnprocessableEntity),
+	ChunkSizeError:        newError(42215, "upload.chunk_size", "分片大小不正确", http.StatusUnprocessableEntity),
+	UploadCompletingError: newError(40902, "upload.completing", "上传正在合并中", http.StatusConflict),
+	UploadIncompleteError: newError(42216, "upload.incomplete", "分片未全部上传", http.StatusUnprocessableEntity),
+	UploadChecksumError:   newError(42217, "upload.checksum", "文件校验失败，请重新上传", http.StatusUnprocessableEntity),
 }
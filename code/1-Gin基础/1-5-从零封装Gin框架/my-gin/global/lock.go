@@ -15,10 +15,25 @@ type Interface interface {
 }
 
 type lock struct {
-	context context.Context
-	name    string
-	owner   string
-	seconds int64
+	context  context.Context
+	redis    *redis.Client
+	name     string
+	owner    string
+	seconds  int64
+	observer LockObserver
+}
+
+// LockObserver 锁操作结果回调，用于统计指标，为 nil 时不统计
+type LockObserver func(operation string, result string)
+
+// Locker 基于 Redis 的分布式锁
+type Locker struct {
+	redis    *redis.Client
+	observer LockObserver
+}
+
+func NewLocker(redis *redis.Client, observer LockObserver) *Locker {
+	return &Locker{redis: redis, observer: observer}
 }
 
 // 释放锁 Lua 脚本，防止任何客户端都能解锁
@@ -31,18 +46,22 @@ end
 `
 
 // Lock 生成锁
-func Lock(name string, seconds int64) Interface {
+func (l *Locker) Lock(name string, seconds int64) Interface {
 	return &lock{
 		context.Background(),
+		l.redis,
 		name,
 		utils.RandString(16),
 		seconds,
+		l.observer,
 	}
 }
 
 // Get 获取锁
 func (l *lock) Get() bool {
-	return App.Redis.SetNX(l.context, l.name, l.owner, time.Duration(l.seconds)*time.Second).Val()
+	ok := l.redis.SetNX(l.context, l.name, l.owner, time.Duration(l.seconds)*time.Second).Val()
+	l.observe("get", lockResult(ok, "acquired", "failed"))
+	return ok
 }
 
 // Block 阻塞一段时间，尝试获取锁
@@ -52,9 +71,11 @@ func (l *lock) Block(seconds int64) bool {
 		if !l.Get() {
 			time.Sleep(time.Duration(1) * time.Second)
 			if time.Now().Unix()-seconds >= starting {
+				l.observe("block", "timeout")
 				return false
 			}
 		} else {
+			l.observe("block", "acquired")
 			return true
 		}
 	}
@@ -63,11 +84,26 @@ func (l *lock) Block(seconds int64) bool {
 // Release 释放锁
 func (l *lock) Release() bool {
 	luaScript := redis.NewScript(releaseLockLuaScript)
-	result := luaScript.Run(l.context, App.Redis, []string{l.name}, l.owner).Val().(int64)
+	result := luaScript.Run(l.context, l.redis, []string{l.name}, l.owner).Val().(int64)
+	l.observe("release", lockResult(result != 0, "released", "not_owner"))
 	return result != 0
 }
 
 // ForceRelease 强制释放锁
 func (l *lock) ForceRelease() {
-	App.Redis.Del(l.context, l.name).Val()
+	l.redis.Del(l.context, l.name).Val()
+	l.observe("force_release", "released")
+}
+
+func (l *lock) observe(operation string, result string) {
+	if l.observer != nil {
+		l.observer(operation, result)
+	}
+}
+
+func lockResult(ok bool, success string, failure string) string {
+	if ok {
+		return success
+	}
+	return failure
 }
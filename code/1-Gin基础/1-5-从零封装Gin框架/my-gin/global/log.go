@@ -0,0 +1,224 @@
+package global
+
+import (
+	"errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"sync"
+	"time"
+)
+
+// 日志模块名称
+const (
+	LogModuleConfig  = "config"
+	LogModuleDB      = "db"
+	LogModuleHttp    = "http"
+	LogModuleJwt     = "jwt"
+	LogModuleStorage = "storage"
+)
+
+// LogLevels 运行时可调整的日志等级：全局等级、模块等级覆盖、临时调试用户
+type LogLevels struct {
+	root       zap.AtomicLevel
+	mu         sync.RWMutex
+	modules    map[string]zapcore.Level
+	debugUsers map[string]time.Time
+}
+
+func NewLogLevels(level zapcore.Level) *LogLevels {
+	return &LogLevels{
+		root:       zap.NewAtomicLevelAt(level),
+		modules:    map[string]zapcore.Level{},
+		debugUsers: map[string]time.Time{},
+	}
+}
+
+// Root 全局日志等级
+func (l *LogLevels) Root() zap.AtomicLevel {
+	return l.root
+}
+
+// SetModuleLevel 设置模块日志等级，level 为空时取消覆盖，跟随全局等级
+func (l *LogLevels) SetModuleLevel(module string, level string) error {
+	if module == "" {
+		return errors.New("module is empty")
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if level == "" {
+		delete(l.modules, module)
+		return nil
+	}
+	lv, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.modules[module] = lv
+	return nil
+}
+
+// SetModules 按配置整体替换模块等级覆盖
+func (l *LogLevels) SetModules(modules map[string]string) error {
+	parsed := make(map[string]zapcore.Level, len(modules))
+	for module, text := range modules {
+		lv, err := zapcore.ParseLevel(text)
+		if err != nil {
+			return err
+		}
+		parsed[module] = lv
+	}
+
+	l.mu.Lock()
+	l.modules = parsed
+	l.mu.Unlock()
+	return nil
+}
+
+// Reset 按配置重置全局及模块等级（配置热更新时调用）
+func (l *LogLevels) Reset(level string, modules map[string]string) error {
+	lv, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	if err = l.SetModules(modules); err != nil {
+		return err
+	}
+	l.root.SetLevel(lv)
+	return nil
+}
+
+// Module 模块日志等级判断器，未覆盖时跟随全局等级
+func (l *LogLevels) Module(module string) zapcore.LevelEnabler {
+	return zap.LevelEnablerFunc(func(lv zapcore.Level) bool {
+		l.mu.RLock()
+		override, ok := l.modules[module]
+		l.mu.RUnlock()
+		if ok {
+			return override.Enabled(lv)
+		}
+		return l.root.Enabled(lv)
+	})
+}
+
+// DebugUser 在 duration 时间内对指定用户开启 debug 日志，由 JwtAuth 中间件应用到请求级 logger
+func (l *LogLevels) DebugUser(userId string, duration time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if duration <= 0 {
+		delete(l.debugUsers, userId)
+		return
+	}
+	l.debugUsers[userId] = time.Now().Add(duration)
+}
+
+// IsDebugUser 用户是否处于临时调试状态
+func (l *LogLevels) IsDebugUser(userId string) bool {
+	if userId == "" {
+		return false
+	}
+	l.mu.RLock()
+	expireAt, ok := l.debugUsers[userId]
+	l.mu.RUnlock()
+	if !ok {
+		return false
+	}
+	if time.Now().After(expireAt) {
+		l.mu.Lock()
+		delete(l.debugUsers, userId)
+		l.mu.Unlock()
+		return false
+	}
+	return true
+}
+
+// Snapshot 当前日志等级信息
+func (l *LogLevels) Snapshot() map[string]interface{} {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	modules := make(map[string]string, len(l.modules))
+	for module, lv := range l.modules {
+		modules[module] = lv.String()
+	}
+	debugUsers := make(map[string]string, len(l.debugUsers))
+	for userId, expireAt := range l.debugUsers {
+		if time.Now().Before(expireAt) {
+			debugUsers[userId] = expireAt.Format(time.DateTime)
+		}
+	}
+	return map[string]interface{}{
+		"level":       l.root.String(),
+		"modules":     modules,
+		"debug_users": debugUsers,
+	}
+}
+
+// levelCore 包装 zapcore.Core，使日志等级判断可以被替换
+type levelCore struct {
+	zapcore.Core
+	enabler zapcore.LevelEnabler
+}
+
+func (c *levelCore) Enabled(lv zapcore.Level) bool {
+	return c.enabler.Enabled(lv)
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{c.Core.With(fields), c.enabler}
+}
+
+func (c *levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(entry.Level) {
+		return checked
+	}
+	// 交由内部 core 判断，保证 Tee 中各输出的等级过滤生效
+	return c.Core.Check(entry, checked)
+}
+
+// WithLevel 返回使用指定等级判断器的 logger，保留已有字段
+func WithLevel(logger *zap.Logger, enabler zapcore.LevelEnabler) *zap.Logger {
+	return logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		if c, ok := core.(*levelCore); ok {
+			core = c.Core
+		}
+		return &levelCore{core, enabler}
+	}))
+}
+
+// 模块名 => moduleLogger
+var moduleLoggers sync.Map
+
+// 缓存的模块 logger 及创建时的根 logger、等级配置，重新初始化日志后按新的根 logger 重建
+type moduleLogger struct {
+	root   *zap.Logger
+	levels *LogLevels
+	logger *zap.Logger
+}
+
+// ModuleLogger 按模块获取 logger，用于依赖注入
+type ModuleLogger func(module string) *zap.Logger
+
+// NewModuleLogger 基于根 logger 及等级配置创建模块 logger 获取函数
+func NewModuleLogger(root *zap.Logger, levels *LogLevels) ModuleLogger {
+	return func(module string) *zap.Logger {
+		return moduleLog(root, levels, module)
+	}
+}
+
+// ModuleLog 获取模块 logger，等级可通过 log.modules 单独覆盖
+func (app *Application) ModuleLog(module string) *zap.Logger {
+	return moduleLog(app.Log, app.LogLevels, module)
+}
+
+func moduleLog(root *zap.Logger, levels *LogLevels, module string) *zap.Logger {
+	if root == nil || levels == nil {
+		return zap.NewNop()
+	}
+	if cached, ok := moduleLoggers.Load(module); ok {
+		if m := cached.(*moduleLogger); m.root == root && m.levels == levels {
+			return m.logger
+		}
+	}
+	m := &moduleLogger{root: root, levels: levels, logger: WithLevel(root.Named(module), levels.Module(module))}
+	moduleLoggers.Store(module, m)
+	return m.logger
+}
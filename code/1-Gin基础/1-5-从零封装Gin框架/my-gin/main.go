@@ -1,11 +1,46 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"log"
 	"my-gin/bootstrap"
 	"my-gin/global"
+	"os"
+	"time"
 )
 
+// 关闭所有组件的总超时时间
+const stopTimeout = 30 * time.Second
+
 func main() {
+	// my-gin config print 输出合并后的生效配置
+	if len(os.Args) > 2 && os.Args[1] == "config" && os.Args[2] == "print" {
+		bootstrap.InitializeConfig()
+		if err := bootstrap.PrintConfig(os.Stdout); err != nil {
+			log.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// my-gin storage migrate -from local -to s3 将文件迁移到另一个存储驱动
+	if len(os.Args) > 2 && os.Args[1] == "storage" && os.Args[2] == "migrate" {
+		bootstrap.InitializeConfig()
+		if err := bootstrap.MigrateStorage(os.Args[3:], os.Stdout); err != nil {
+			log.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := run(); err != nil {
+		log.Println(err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
 	// 初始化配置
 	bootstrap.InitializeConfig()
 
@@ -13,29 +48,19 @@ func main() {
 	global.App.Log = bootstrap.InitializeLog()
 	global.App.Log.Info("log init success!")
 
-	// 初始化数据库
-	global.App.DB = bootstrap.InitializeDB()
-
-	// 初始化验证器
-	bootstrap.InitializeValidator()
+	// 程序退出前，刷新并关闭日志
+	defer bootstrap.CloseLog()
 
-	// 初始化 Redis
-	global.App.Redis = bootstrap.InitializeRedis()
+	// 按依赖顺序启动告警、链路追踪、数据库、验证器、Redis、文件系统、指标及健康检查
+	global.App.Lifecycle = bootstrap.InitializeLifecycle()
+	if err := global.App.Lifecycle.Start(context.Background()); err != nil {
+		return err
+	}
 
-	// 初始化文件系统
-	bootstrap.InitializeStorage()
-
-	// 程序关闭前，释放数据库连接
-	defer func() {
-		if global.App.DB != nil {
-			db, _ := global.App.DB.DB()
-			err := db.Close()
-			if err != nil {
-				global.App.Log.Error("failed to close the database in the process.")
-			}
-		}
-	}()
+	// 启动服务器，服务器关闭后逆序关闭各组件
+	serverErr := bootstrap.RunServer(wireHttp(bootstrap.StartedComponents()))
 
-	// 启动服务器
-	bootstrap.RunServer()
+	ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+	defer cancel()
+	return errors.Join(serverErr, global.App.Lifecycle.Stop(ctx))
 }
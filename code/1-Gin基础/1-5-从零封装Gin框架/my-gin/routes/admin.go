@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"my-gin/app/controller/admin"
+	"my-gin/app/middleware"
+	"my-gin/config"
+)
+
+// AdminRoutes admin 分组路由依赖的控制器
+type AdminRoutes struct {
+	Conf *config.Store
+	Log  *admin.LogController
+}
+
+// SetAdminGroupRoutes 定义 admin 分组路由
+func (r *AdminRoutes) SetAdminGroupRoutes(router *gin.RouterGroup) {
+	adminRouter := router.Group("").Use(middleware.AdminAuth(r.Conf))
+	{
+		adminRouter.GET("/log/level", r.Log.GetLogLevel)
+		adminRouter.PUT("/log/level", r.Log.SetLogLevel)
+		adminRouter.POST("/log/debug_user", r.Log.DebugUser)
+		adminRouter.DELETE("/log/debug_user", r.Log.CancelDebugUser)
+	}
+}
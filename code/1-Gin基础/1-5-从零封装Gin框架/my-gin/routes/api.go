@@ -10,8 +10,17 @@ import (
 	"time"
 )
 
+// ApiRoutes api 分组路由依赖的控制器及中间件
+type ApiRoutes struct {
+	Auth     *app.AuthController
+	Upload   *common.UploadController
+	Download *common.DownloadController
+	Media    *common.MediaController
+	JwtAuth  *middleware.JwtAuth
+}
+
 // SetApiGroupRoutes 定义 api 分组路由
-func SetApiGroupRoutes(router *gin.RouterGroup) {
+func (r *ApiRoutes) SetApiGroupRoutes(router *gin.RouterGroup) {
 	router.GET("/ping", func(c *gin.Context) {
 		c.String(http.StatusOK, "pong")
 	})
@@ -21,12 +30,26 @@ func SetApiGroupRoutes(router *gin.RouterGroup) {
 		c.String(http.StatusOK, "success")
 	})
 
-	router.POST("/auth/register", app.Register)
-	router.POST("/auth/login", app.Login)
-	authRouter := router.Group("").Use(middleware.JWTAuth(service.AppGuardName))
+	router.POST("/auth/register", r.Auth.Register)
+	router.POST("/auth/login", r.Auth.Login)
+	// 签名下载链接，绑定用户的链接需携带该用户的 token
+	router.GET("/download", r.JwtAuth.Optional(service.AppGuardName), r.Download.Download)
+	authRouter := router.Group("").Use(r.JwtAuth.Handle(service.AppGuardName))
 	{
-		authRouter.POST("/auth/info", app.Info)
-		authRouter.POST("/auth/logout", app.LogOut)
-		authRouter.POST("/image_upload", common.ImageUpload)
+		authRouter.POST("/auth/info", r.Auth.Info)
+		authRouter.POST("/auth/logout", r.Auth.LogOut)
+		authRouter.POST("/image_upload", r.Upload.ImageUpload)
+		authRouter.POST("/file_upload", r.Upload.FileUpload)
+		// 分片上传
+		authRouter.POST("/uploads", r.Upload.InitUpload)
+		authRouter.GET("/uploads/:id", r.Upload.UploadStatus)
+		authRouter.PUT("/uploads/:id/chunks/:index", r.Upload.UploadChunk)
+		authRouter.POST("/uploads/:id/complete", r.Upload.CompleteUpload)
+		authRouter.DELETE("/uploads/:id", r.Upload.AbortUpload)
+		// 我的文件
+		authRouter.GET("/media", r.Media.List)
+		authRouter.GET("/media/:id", r.Media.Show)
+		authRouter.DELETE("/media/:id", r.Media.Delete)
+		authRouter.GET("/media/:id/url", r.Download.SignedUrl)
 	}
 }
@@ -0,0 +1,6 @@
+package routes
+
+import "github.com/google/wire"
+
+// ProviderSet 路由 provider
+var ProviderSet = wire.NewSet(wire.Struct(new(ApiRoutes), "*"), wire.Struct(new(AdminRoutes), "*"))
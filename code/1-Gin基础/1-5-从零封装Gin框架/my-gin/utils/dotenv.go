@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LoadDotEnv 读取 .env 文件写入环境变量，已存在的环境变量不会被覆盖，文件不存在时忽略
+func LoadDotEnv(filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return fmt.Errorf("%s:%d: invalid line", filename, lineNo)
+		}
+		if _, exists := os.LookupEnv(key); exists {
+			continue
+		}
+		if err := os.Setenv(key, parseDotEnvValue(strings.TrimSpace(value))); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// 去掉引号，未加引号时去掉行尾注释
+func parseDotEnvValue(value string) string {
+	if len(value) >= 2 {
+		switch quote := value[0]; quote {
+		case '"', '\'':
+			if end := strings.LastIndexByte(value, quote); end > 0 {
+				value = value[1:end]
+				if quote == '"' {
+					value = strings.NewReplacer(`\n`, "\n", `\"`, `"`).Replace(value)
+				}
+				return value
+			}
+		}
+	}
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	return value
+}
@@ -0,0 +1,173 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+const RedactMask = "******"
+
+// 默认脱敏字段，字段名不区分大小写
+var defaultRedactFields = []string{
+	"password", "mobile", "phone", "authorization", "token", "access_token", "refresh_token",
+	"new-token", "secret", "id_card", "id_number",
+}
+
+// 默认脱敏规则：身份证号、手机号保留首尾，令牌类整体替换
+var defaultRedactRules = []redactRule{
+	{regexp.MustCompile(`\b(\d{3})\d{11}(\d{3}[\dXx])\b`), "${1}***********${2}"},
+	{regexp.MustCompile(`\b(1[3-9]\d)\d{4}(\d{4})\b`), "${1}****${2}"},
+	{regexp.MustCompile(`(?i)\bbearer\s+[\w\-.~+/]+=*`), "Bearer " + RedactMask},
+	{regexp.MustCompile(`\beyJ[\w-]+\.[\w-]+\.[\w-]+`), RedactMask},
+}
+
+type redactRule struct {
+	pattern *regexp.Regexp
+	replace string
+}
+
+// Redactor 日志及错误信息脱敏
+type Redactor struct {
+	fields map[string]bool
+	rules  []redactRule
+}
+
+// NewRedactor 在默认规则基础上追加字段名及正则（匹配内容整体替换）
+func NewRedactor(fields []string, patterns []string) (*Redactor, error) {
+	r := &Redactor{
+		fields: make(map[string]bool, len(defaultRedactFields)+len(fields)),
+		rules:  append([]redactRule{}, defaultRedactRules...),
+	}
+	for _, field := range append(defaultRedactFields, fields...) {
+		r.fields[strings.ToLower(field)] = true
+	}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		r.rules = append(r.rules, redactRule{re, RedactMask})
+	}
+	return r, nil
+}
+
+// MaskMobile 手机号部分脱敏，如 138****1234
+func MaskMobile(mobile string) string {
+	if len(mobile) != 11 {
+		return RedactMask
+	}
+	return mobile[:3] + "****" + mobile[7:]
+}
+
+// IsSensitive 字段是否需要脱敏
+func (r *Redactor) IsSensitive(key string) bool {
+	return r != nil && r.fields[strings.ToLower(key)]
+}
+
+// String 按正则规则脱敏字符串内容
+func (r *Redactor) String(s string) string {
+	if r == nil {
+		return s
+	}
+	for _, rule := range r.rules {
+		s = rule.pattern.ReplaceAllString(s, rule.replace)
+	}
+	return s
+}
+
+// Value 按字段名脱敏，敏感字段整体替换（手机号保留首尾），其余按规则脱敏
+func (r *Redactor) Value(key string, value string) string {
+	if !r.IsSensitive(key) {
+		return r.String(value)
+	}
+	switch strings.ToLower(key) {
+	case "mobile", "phone":
+		return MaskMobile(value)
+	}
+	return RedactMask
+}
+
+// Values 脱敏表单、查询参数
+func (r *Redactor) Values(values url.Values) url.Values {
+	result := make(url.Values, len(values))
+	for key, items := range values {
+		for _, item := range items {
+			result.Add(key, r.Value(key, item))
+		}
+	}
+	return result
+}
+
+// Header 脱敏请求头
+func (r *Redactor) Header(header http.Header) http.Header {
+	result := make(http.Header, len(header))
+	for key, items := range header {
+		for _, item := range items {
+			result.Add(key, r.Value(key, item))
+		}
+	}
+	return result
+}
+
+// Any 递归脱敏 json 解析后的数据，结构体、指定类型的 map 及 slice 先按 json 编码转换后脱敏
+func (r *Redactor) Any(data interface{}) interface{} {
+	switch v := data.(type) {
+	case string:
+		return r.String(v)
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			if s, ok := item.(string); ok {
+				result[key] = r.Value(key, s)
+			} else if r.IsSensitive(key) && item != nil {
+				result[key] = RedactMask
+			} else {
+				result[key] = r.Any(item)
+			}
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = r.Any(item)
+		}
+		return result
+	case url.Values:
+		return r.Values(v)
+	case http.Header:
+		return r.Header(v)
+	}
+	return r.reflectAny(data)
+}
+
+// 结构体等复合类型转换为 json 解析后的数据再脱敏，基础类型原样返回
+func (r *Redactor) reflectAny(data interface{}) interface{} {
+	value := reflect.ValueOf(data)
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return data
+		}
+		value = value.Elem()
+	}
+	switch value.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+	default:
+		return data
+	}
+
+	// 无法编码时退回字符串形式，避免原样输出敏感内容
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return r.String(fmt.Sprintf("%+v", data))
+	}
+	var decoded interface{}
+	if err = json.Unmarshal(encoded, &decoded); err != nil {
+		return r.String(string(encoded))
+	}
+	return r.Any(decoded)
+}
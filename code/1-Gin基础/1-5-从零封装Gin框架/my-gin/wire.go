@@ -0,0 +1,20 @@
+//go:build wireinject
+// +build wireinject
+
+package main
+
+import (
+	"github.com/google/wire"
+	"my-gin/app/controller/admin"
+	"my-gin/app/controller/app"
+	"my-gin/app/controller/common"
+	"my-gin/app/middleware"
+	"my-gin/app/service"
+	"my-gin/bootstrap"
+	"my-gin/routes"
+)
+
+// wireHttp 组装 http 服务依赖，修改后执行 wire 重新生成 wire_gen.go
+func wireHttp(components *bootstrap.Components) *bootstrap.Http {
+	panic(wire.Build(bootstrap.ProviderSet, service.ProviderSet, middleware.ProviderSet, app.ProviderSet, common.ProviderSet, admin.ProviderSet, routes.ProviderSet))
+}
@@ -0,0 +1,78 @@
+// Code generated by Wire. DO NOT EDIT.
+
+//go:generate go run -mod=mod github.com/google/wire/cmd/wire
+//go:build !wireinject
+// +build !wireinject
+
+package main
+
+import (
+	"my-gin/app/controller/admin"
+	"my-gin/app/controller/app"
+	"my-gin/app/controller/common"
+	"my-gin/app/middleware"
+	"my-gin/app/service"
+	"my-gin/bootstrap"
+	"my-gin/global"
+	"my-gin/routes"
+)
+
+// Injectors from wire.go:
+
+// wireHttp 组装 http 服务依赖，修改后执行 wire 重新生成 wire_gen.go
+func wireHttp(components *bootstrap.Components) *bootstrap.Http {
+	store := components.Config
+	logger := components.Log
+	logLevels := components.LogLevels
+	moduleLogger := global.NewModuleLogger(logger, logLevels)
+	requestId := middleware.NewRequestId(moduleLogger)
+	redactor := components.Redactor
+	accessLog := middleware.NewAccessLog(store, redactor)
+	dispatcher := components.Alert
+	recovery := middleware.NewRecovery(store, redactor, dispatcher)
+	errorHandler := middleware.NewErrorHandler(store)
+	cors := middleware.NewCors(store)
+	middlewareGlobal := &middleware.Global{
+		RequestId:    requestId,
+		AccessLog:    accessLog,
+		Recovery:     recovery,
+		ErrorHandler: errorHandler,
+		Cors:         cors,
+	}
+	db := components.DB
+	userService := service.NewUserService(db)
+	client := components.Redis
+	jwtService := service.NewJwtService(store, client, userService)
+	authController := app.NewAuthController(userService, jwtService)
+	disks := bootstrap.NewDisks(store)
+	signer := components.UrlSigner
+	lockObserver := bootstrap.NewLockObserver()
+	locker := global.NewLocker(client, lockObserver)
+	mediaService := service.NewMediaService(store, db, client, disks, signer, locker)
+	uploadController := common.NewUploadController(mediaService)
+	downloadController := common.NewDownloadController(signer, mediaService)
+	mediaController := common.NewMediaController(mediaService)
+	jwtAuth := middleware.NewJwtAuth(store, jwtService, locker, logLevels, moduleLogger)
+	apiRoutes := &routes.ApiRoutes{
+		Auth:     authController,
+		Upload:   uploadController,
+		Download: downloadController,
+		Media:    mediaController,
+		JwtAuth:  jwtAuth,
+	}
+	logController := admin.NewLogController(logLevels)
+	adminRoutes := &routes.AdminRoutes{
+		Conf: store,
+		Log:  logController,
+	}
+	registry := components.Health
+	healthController := common.NewHealthController(registry)
+	http := &bootstrap.Http{
+		Conf:       store,
+		Middleware: middlewareGlobal,
+		Api:        apiRoutes,
+		Admin:      adminRoutes,
+		Health:     healthController,
+	}
+	return http
+}